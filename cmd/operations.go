@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+	"vmuser/config"
+	"vmuser/database"
+)
+
+// OperationsPollInterval is how often FollowOperations checks the operation log for new entries.
+const OperationsPollInterval = time.Second
+
+// FollowOperations prints operation log entries to out as they are recorded, starting after the latest existing
+// entry, until ctx is cancelled.
+func FollowOperations(ctx context.Context, cfg *config.VMUserConfig, out io.Writer) error {
+	db, err := database.GetConnection(&cfg.Turso)
+	if err != nil {
+		return fmt.Errorf("error getting database connection: %w", err)
+	}
+
+	lastID, err := database.LatestOperationID(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTimestamp\tOperation\tDetails")
+	fmt.Fprintln(w, "---\t---------\t---------\t-------")
+	w.Flush()
+
+	ticker := time.NewTicker(OperationsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		entries, err := database.OperationsSince(ctx, db, lastID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Error("Error polling operation log", "error", err)
+			continue
+		}
+
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+				entry.ID,
+				entry.Timestamp.Format("2006-01-02 15:04:05"),
+				entry.Operation,
+				entry.Details)
+			lastID = entry.ID
+		}
+		w.Flush()
+	}
+}
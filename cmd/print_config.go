@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"vmuser/config"
+)
+
+// Formats accepted by PrintConfig
+const (
+	ConfigFormatTOML = "toml"
+	ConfigFormatJSON = "json"
+)
+
+// configFieldJSON is a single setting in PrintConfig's JSON output
+type configFieldJSON struct {
+	Value  any           `json:"value"`
+	Source config.Source `json:"source"`
+	Env    []string      `json:"env,omitempty"`
+}
+
+// PrintConfig writes the effective configuration, with secrets redacted, in the given format (ConfigFormatTOML or
+// ConfigFormatJSON). Each setting is annotated with whether it came from the file, the environment or a default.
+func PrintConfig(w io.Writer, cfg *config.VMUserConfig, format string) error {
+	fields, err := cfg.Fields()
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case ConfigFormatTOML:
+		return printConfigTOML(w, cfg.Path, fields)
+	case ConfigFormatJSON:
+		return printConfigJSON(w, cfg.Path, fields)
+	default:
+		return fmt.Errorf("unknown config format %q, expected %s or %s", format, ConfigFormatTOML, ConfigFormatJSON)
+	}
+}
+
+func printConfigTOML(w io.Writer, path string, fields []config.Field) error {
+	if path == "" {
+		fmt.Fprintln(w, "# No config file found, loaded from environment")
+	} else {
+		fmt.Fprintf(w, "# Loaded from %s\n", path)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var section string
+	for _, field := range fields {
+		if field.Section != section {
+			section = field.Section
+			fmt.Fprintf(tw, "\n[%s]\n", section)
+		}
+
+		source := string(field.Source)
+		if len(field.Env) > 0 {
+			source += " (" + strings.Join(field.Env, ", ") + ")"
+		}
+		fmt.Fprintf(tw, "%s = %s\t# %s\n", field.Key, tomlValue(field.Value), source)
+	}
+	return tw.Flush()
+}
+
+// tomlValue formats the scalar config values as TOML literals
+func tomlValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func printConfigJSON(w io.Writer, path string, fields []config.Field) error {
+	sections := make(map[string]map[string]configFieldJSON)
+	for _, field := range fields {
+		if sections[field.Section] == nil {
+			sections[field.Section] = make(map[string]configFieldJSON)
+		}
+		sections[field.Section][field.Key] = configFieldJSON{Value: field.Value, Source: field.Source, Env: field.Env}
+	}
+
+	out := struct {
+		Path     string                                `json:"path"`
+		Sections map[string]map[string]configFieldJSON `json:"sections"`
+	}{Path: path, Sections: sections}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -5,13 +5,17 @@ import (
         "database/sql"
         "fmt"
         "os"
+        "path/filepath"
         "text/tabwriter"
+        "time"
         "vmuser/config"
         "vmuser/database"
         "vmuser/pkg/reports"
 )
 
-func AddReport(ctx context.Context, cfg *config.VMUserConfig, filePath string) error {
+// AddReport adds the report file at filePath to the database. If a report with identical content already exists,
+// reports.ErrReportExists is returned unless updateExisting is set.
+func AddReport(ctx context.Context, cfg *config.VMUserConfig, filePath string, updateExisting bool) error {
         // Check if file exists
         if _, err := os.Stat(filePath); os.IsNotExist(err) {
                 return fmt.Errorf("report file does not exist: %s", filePath)
@@ -22,7 +26,7 @@ func AddReport(ctx context.Context, cfg *config.VMUserConfig, filePath string) e
                 return fmt.Errorf("error getting database connection: %w", err)
         }
 
-        err = reports.AddReportToDatabase(ctx, db, filePath)
+        _, err = reports.AddReportToDatabase(ctx, db, filePath, updateExisting)
         if err != nil {
                 return fmt.Errorf("error adding report to database: %w", err)
         }
@@ -30,6 +34,39 @@ func AddReport(ctx context.Context, cfg *config.VMUserConfig, filePath string) e
         return nil
 }
 
+// AddReportsDir adds every regular file in dirPath as a report, in a single batch. Files that cannot be read are
+// skipped and reported in the returned error; the IDs of the reports that were added are always returned.
+func AddReportsDir(ctx context.Context, cfg *config.VMUserConfig, dirPath string) ([]int64, error) {
+        matches, err := filepath.Glob(filepath.Join(dirPath, "*"))
+        if err != nil {
+                return nil, fmt.Errorf("error listing report directory: %w", err)
+        }
+
+        var paths []string
+        for _, match := range matches {
+                info, err := os.Stat(match)
+                if err != nil || !info.Mode().IsRegular() {
+                        continue
+                }
+                paths = append(paths, match)
+        }
+        if len(paths) == 0 {
+                return nil, fmt.Errorf("no report files found in directory: %s", dirPath)
+        }
+
+        db, err := database.GetConnection(&cfg.Turso)
+        if err != nil {
+                return nil, fmt.Errorf("error getting database connection: %w", err)
+        }
+
+        ids, err := reports.AddReportsBatch(ctx, db, paths)
+        if err != nil {
+                return ids, fmt.Errorf("error adding reports to database: %w", err)
+        }
+
+        return ids, nil
+}
+
 // GetReportByID retrieves a specific report by its ID
 func GetReportByID(ctx context.Context, cfg *config.VMUserConfig, id int64) (*reports.Report, error) {
         db, err := database.GetConnection(&cfg.Turso)
@@ -63,11 +100,57 @@ func ListAllReports(ctx context.Context, cfg *config.VMUserConfig) ([]reports.Re
         return reportList, nil
 }
 
-// DisplayReport formats and prints a single report
-func DisplayReport(w *tabwriter.Writer, report *reports.Report) {
-        fmt.Fprintf(w, "Report ID:\t%d\n", report.ID)
-        fmt.Fprintf(w, "Filename:\t%s\n", report.Filename)
-        fmt.Fprintf(w, "Created At:\t%s\n", report.CreatedAt.Format("2006-01-02 15:04:05"))
-        fmt.Fprintf(w, "Updated At:\t%s\n", report.UpdatedAt.Format("2006-01-02 15:04:05"))
-        fmt.Fprintf(w, "Content:\n%s\n", report.Content)
+// ListReportsBetween retrieves the reports created in [from, to) from the database, without their content
+func ListReportsBetween(ctx context.Context, cfg *config.VMUserConfig, from, to time.Time) ([]reports.Report, error) {
+        db, err := database.GetConnection(&cfg.Turso)
+        if err != nil {
+                return nil, fmt.Errorf("error getting database connection: %w", err)
+        }
+
+        reportList, err := reports.ListReportsBetween(ctx, db, from, to)
+        if err != nil {
+                return nil, fmt.Errorf("error retrieving reports: %w", err)
+        }
+
+        return reportList, nil
+}
+
+// PruneReports deletes the reports created more than olderThan ago and returns how many were removed
+func PruneReports(ctx context.Context, cfg *config.VMUserConfig, olderThan time.Duration) (int64, error) {
+        if olderThan <= 0 {
+                return 0, fmt.Errorf("retention period must be positive, got %s", olderThan)
+        }
+
+        db, err := database.GetConnection(&cfg.Turso)
+        if err != nil {
+                return 0, fmt.Errorf("error getting database connection: %w", err)
+        }
+
+        deleted, err := reports.DeleteReportsOlderThan(ctx, db, time.Now().Add(-olderThan))
+        if err != nil {
+                return 0, fmt.Errorf("error pruning reports: %w", err)
+        }
+
+        return deleted, nil
+}
+
+// DisplayReport formats and prints a single report, colorizing labels and the ID when style allows
+func DisplayReport(w *tabwriter.Writer, report *reports.Report, style Style) {
+        fmt.Fprintf(w, "%s\t%s\n", style.Header("Report ID:"), style.ID(fmt.Sprintf("%d", report.ID)))
+        fmt.Fprintf(w, "%s\t%s\n", style.Header("Filename:"), report.Filename)
+        fmt.Fprintf(w, "%s\t%s\n", style.Header("Created At:"), report.CreatedAt.Format("2006-01-02 15:04:05"))
+        fmt.Fprintf(w, "%s\t%s\n", style.Header("Updated At:"), report.UpdatedAt.Format("2006-01-02 15:04:05"))
+        fmt.Fprintf(w, "%s\n%s\n", style.Header("Content:"), report.Content)
+}
+
+// DisplayReportList prints a table of reports, one per line, colorizing the header and IDs when style allows
+func DisplayReportList(w *tabwriter.Writer, reportList []reports.Report, style Style) {
+        fmt.Fprintf(w, "%s\t%s\t%s\n", style.Header("ID"), style.Header("Filename"), style.Header("Created At"))
+        fmt.Fprintf(w, "%s\t%s\t%s\n", style.Header("---"), style.Header("--------"), style.Header("----------"))
+        for _, r := range reportList {
+                fmt.Fprintf(w, "%s\t%s\t%s\n",
+                        style.ID(fmt.Sprintf("%d", r.ID)),
+                        style.Plain(r.Filename),
+                        r.CreatedAt.Format("2006-01-02 15:04:05"))
+        }
 }
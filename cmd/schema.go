@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"vmuser/config"
+	"vmuser/database"
+)
+
+// VerifySchema checks the database schema for drift and prints each discrepancy found. With repair, missing tables,
+// columns, indexes and triggers are created first, and only what could not be repaired is printed. It fails if any
+// discrepancy remains.
+func VerifySchema(ctx context.Context, cfg *config.VMUserConfig, w io.Writer, repair bool) error {
+	db, err := database.GetConnection(&cfg.Turso)
+	if err != nil {
+		return fmt.Errorf("error getting database connection: %w", err)
+	}
+	defer db.Close()
+
+	var problems []string
+	if repair {
+		problems, err = database.Repair(ctx, db)
+	} else {
+		problems, err = database.Verify(ctx, db)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "Database schema is up to date")
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(w, problem)
+	}
+	if !repair {
+		return fmt.Errorf("found %d schema discrepancies, run with -repair-db to fix them", len(problems))
+	}
+	return fmt.Errorf("%d schema discrepancies remain after repair", len(problems))
+}
@@ -2,18 +2,49 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 	"vmuser/config"
+	"vmuser/database"
+	"vmuser/pkg/llm"
 	"vmuser/server"
 )
 
+// The server waits for the database for up to about a minute at startup, since under container orchestration it can
+// start before the database is reachable
+const (
+	dbConnectAttempts = 6
+	dbConnectBackoff  = 2 * time.Second
+)
+
 func Server(appCtx context.Context, cfg *config.VMUserConfig) error {
 	serverCfg := server.Config{
-		Port: cfg.Server.Port,
+		Port:         cfg.Server.Port,
+		MaxBodyBytes: cfg.Server.MaxBodyBytes,
+		Metrics:      cfg.Server.Metrics,
+	}
+
+	llmClient, err := llm.NewClient(cfg.LLM)
+	switch {
+	case errors.Is(err, llm.ErrNoProvider):
+		slog.Info("No LLM API key configured, completions are disabled")
+	case err != nil:
+		return fmt.Errorf("error creating LLM client: %w", err)
+	default:
+		serverCfg.LLM = llmClient
 	}
-	s := server.NewServer(&serverCfg)
 
-	err := s.Start(appCtx)
+	db, err := database.GetConnectionWithRetry(appCtx, &cfg.Turso, dbConnectAttempts, dbConnectBackoff)
+	if err != nil {
+		return fmt.Errorf("error getting database connection: %w", err)
+	}
+	defer db.Close()
+
+	s := server.NewServer(&serverCfg, db)
+
+	err = s.Start(appCtx)
 	if err != nil {
 		slog.Error("Error starting server", "err", err)
 	}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+	"vmuser/config"
+	"vmuser/database"
+	"vmuser/pkg/reports"
+)
+
+// StatusTimeout bounds each database check made by Status.
+const StatusTimeout = 5 * time.Second
+
+// StatusReport summarizes how the application is wired up. Each subsystem check records its own error, so one failure
+// does not hide the state of the others.
+type StatusReport struct {
+	ConfigPath string
+	TursoURL   string
+	ServerPort string
+
+	DatabaseErr error
+
+	ReportCount int64
+	ReportsErr  error
+
+	FileCount  int
+	DirCount   int
+	TotalBytes int64
+	FilesErr   error
+}
+
+// Status checks the configuration, database connectivity, report store and virtual filesystem. Every check is run
+// even when an earlier one fails; the returned error joins the failures, and the report is always filled in as far as
+// possible.
+func Status(ctx context.Context, cfg *config.VMUserConfig) (StatusReport, error) {
+	report := StatusReport{
+		ConfigPath: cfg.Path,
+		TursoURL:   config.RedactURL(cfg.Turso.URL),
+		ServerPort: cfg.Server.Port,
+	}
+
+	db, err := database.GetConnection(&cfg.Turso)
+	if err != nil {
+		report.DatabaseErr = err
+		return report, fmt.Errorf("database: %w", err)
+	}
+	defer db.Close()
+
+	pingCtx, cancel := context.WithTimeout(ctx, StatusTimeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		report.DatabaseErr = err
+		// Without a reachable database the remaining checks can only fail the same way
+		return report, fmt.Errorf("database: %w", err)
+	}
+
+	countCtx, cancelCount := context.WithTimeout(ctx, StatusTimeout)
+	defer cancelCount()
+	report.ReportCount, report.ReportsErr = reports.CountReports(countCtx, db)
+
+	statsCtx, cancelStats := context.WithTimeout(ctx, StatusTimeout)
+	defer cancelStats()
+	fs := database.NewTursoFileSystemFromDB(db)
+	report.FileCount, report.DirCount, report.TotalBytes, report.FilesErr = fs.DirStatsContext(statsCtx, "")
+
+	var errs []error
+	if report.ReportsErr != nil {
+		errs = append(errs, fmt.Errorf("reports: %w", report.ReportsErr))
+	}
+	if report.FilesErr != nil {
+		errs = append(errs, fmt.Errorf("virtual filesystem: %w", report.FilesErr))
+	}
+	return report, errors.Join(errs...)
+}
+
+// DisplayStatus prints a status report, one subsystem per line.
+func DisplayStatus(w io.Writer, report StatusReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	configPath := report.ConfigPath
+	if configPath == "" {
+		configPath = "(none found, using environment)"
+	}
+	fmt.Fprintf(tw, "Config file:\t%s\n", configPath)
+	fmt.Fprintf(tw, "Turso URL:\t%s\n", report.TursoURL)
+	fmt.Fprintf(tw, "Database:\t%s\n", statusLine(report.DatabaseErr, "reachable"))
+
+	if report.DatabaseErr != nil {
+		fmt.Fprintf(tw, "Reports:\tskipped\n")
+		fmt.Fprintf(tw, "Virtual files:\tskipped\n")
+	} else {
+		fmt.Fprintf(tw, "Reports:\t%s\n", statusLine(report.ReportsErr, fmt.Sprintf("%d", report.ReportCount)))
+		fmt.Fprintf(tw, "Virtual files:\t%s\n", statusLine(report.FilesErr,
+			fmt.Sprintf("%d files, %d directories, %d bytes", report.FileCount, report.DirCount, report.TotalBytes)))
+	}
+
+	fmt.Fprintf(tw, "Server port:\t%s\n", report.ServerPort)
+}
+
+func statusLine(err error, ok string) string {
+	if err != nil {
+		return "FAILED: " + err.Error()
+	}
+	return ok
+}
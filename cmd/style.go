@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"golang.org/x/term"
+	"os"
+)
+
+// ANSI SGR codes used by Style. All are two digits so every painted cell carries the same number of invisible bytes,
+// which keeps tabwriter columns aligned as long as every cell in a column is painted.
+const (
+	sgrReset   = "\x1b[0m"
+	sgrBold    = "01"
+	sgrCyan    = "36"
+	sgrDefault = "39"
+)
+
+// Style colorizes CLI output. The zero value emits plain text.
+type Style struct {
+	color bool
+}
+
+// NewStyle returns a Style that colorizes output written to out only when out is a terminal, noColor is false and the
+// NO_COLOR environment variable is unset, so piped output stays plain and parseable.
+func NewStyle(out *os.File, noColor bool) Style {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return Style{}
+	}
+	return Style{color: term.IsTerminal(int(out.Fd()))}
+}
+
+// Header renders column headers and labels in bold.
+func (s Style) Header(text string) string {
+	return s.paint(sgrBold, text)
+}
+
+// ID renders identifiers in cyan.
+func (s Style) ID(text string) string {
+	return s.paint(sgrCyan, text)
+}
+
+// Plain renders text in the default color. Use it for cells sharing a column with painted cells.
+func (s Style) Plain(text string) string {
+	return s.paint(sgrDefault, text)
+}
+
+func (s Style) paint(code, text string) string {
+	if !s.color {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + sgrReset
+}
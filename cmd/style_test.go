@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"text/tabwriter"
+	"time"
+	"vmuser/pkg/reports"
+)
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestDisplayReportListColorKeepsAlignment(t *testing.T) {
+	reportList := []reports.Report{
+		{ID: 7, Filename: "short.md", CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 1234, Filename: "a-much-longer-name.md", CreatedAt: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	render := func(style Style) string {
+		var buf bytes.Buffer
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		DisplayReportList(w, reportList, style)
+		w.Flush()
+		return buf.String()
+	}
+
+	plain := render(Style{})
+	colored := render(Style{color: true})
+
+	if strings.Contains(plain, "\x1b[") {
+		t.Fatalf("plain output contains escape codes: %q", plain)
+	}
+	if !strings.Contains(colored, "\x1b[") {
+		t.Fatalf("colored output has no escape codes")
+	}
+	if stripped := ansiPattern.ReplaceAllString(colored, ""); stripped != plain {
+		t.Fatalf("colored output is misaligned:\n%s\nwant:\n%s", stripped, plain)
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRelativeDuration parses a Go duration ("36h", "90m") or a whole number of days ("7d").
+func ParseRelativeDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q: %w", value, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	return d, nil
+}
+
+// ParseTimeFlag parses a point in time given either as RFC3339 or as a duration before now (see
+// ParseRelativeDuration), e.g. "7d" for a week ago. An empty value returns the zero time.
+func ParseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := ParseRelativeDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 time or relative duration: %w", err)
+	}
+	return now.Add(-d), nil
+}
@@ -17,6 +17,7 @@ func TUI(appCtx context.Context, cfg *config.VMUserConfig) error {
 				Title("XBRL-Go").Description("Select an option").
 				Options(
 					huh.NewOption("Home", "home"),
+					huh.NewOption("View reports", "reports"),
 					huh.NewOption("Start server", "server"),
 				).
 				Value(&function),
@@ -32,6 +33,11 @@ func TUI(appCtx context.Context, cfg *config.VMUserConfig) error {
 	switch function {
 	case "home":
 		slog.Info("Displaying home")
+	case "reports":
+		if err := ReportViewer(appCtx, cfg); err != nil {
+			slog.Error("Error viewing reports", "error", err)
+			return err
+		}
 	case "server":
 		err = Server(appCtx, cfg)
 		slog.Error("Error starting server", "error", err)
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+	"vmuser/config"
+)
+
+// reportListHeight is the number of reports shown at once in the TUI report list.
+const reportListHeight = 15
+
+// backToMenu is the report list value that leaves the report viewer.
+const backToMenu int64 = -1
+
+// ReportViewer lets the user pick a report from a searchable list and read it in a scrollable pager, returning to
+// the list when the pager is closed, until the user goes back.
+func ReportViewer(ctx context.Context, cfg *config.VMUserConfig) error {
+	for {
+		reportList, err := ListAllReports(ctx, cfg)
+		if err != nil {
+			return err
+		}
+
+		options := []huh.Option[int64]{huh.NewOption("<- Back", backToMenu)}
+		for _, r := range reportList {
+			label := fmt.Sprintf("%d  %s  %s", r.ID, r.Filename, r.CreatedAt.Format("2006-01-02 15:04"))
+			options = append(options, huh.NewOption(label, r.ID))
+		}
+
+		id := backToMenu
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[int64]().
+					Title("Reports").Description("Type / to search, enter to open").
+					Options(options...).
+					Filtering(true).
+					Height(reportListHeight).
+					Value(&id),
+			),
+		).WithTheme(huh.ThemeBase16())
+
+		if err := form.RunWithContext(ctx); err != nil {
+			if errors.Is(err, huh.ErrUserAborted) {
+				return nil
+			}
+			return err
+		}
+		if id == backToMenu {
+			return nil
+		}
+
+		report, err := GetReportByID(ctx, cfg, id)
+		if err != nil {
+			return err
+		}
+
+		title := fmt.Sprintf("#%d %s (%s)", report.ID, report.Filename, report.CreatedAt.Format("2006-01-02 15:04:05"))
+		if _, err := tea.NewProgram(newReportPager(title, report.Content), tea.WithAltScreen(), tea.WithContext(ctx)).Run(); err != nil {
+			return fmt.Errorf("error running report pager: %w", err)
+		}
+	}
+}
+
+var (
+	pagerTitleStyle  = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	pagerFooterStyle = lipgloss.NewStyle().Faint(true).Padding(0, 1)
+)
+
+// reportPager is a bubbletea model showing a report's content in a scrollable viewport.
+type reportPager struct {
+	title    string
+	content  string
+	viewport viewport.Model
+	ready    bool
+}
+
+func newReportPager(title, content string) reportPager {
+	return reportPager{title: title, content: content}
+}
+
+func (m reportPager) Init() tea.Cmd {
+	return nil
+}
+
+func (m reportPager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		height := msg.Height - lipgloss.Height(m.header()) - lipgloss.Height(m.footer())
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.viewport.SetContent(m.content)
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = height
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m reportPager) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+	return strings.Join([]string{m.header(), m.viewport.View(), m.footer()}, "\n")
+}
+
+func (m reportPager) header() string {
+	return pagerTitleStyle.Render(m.title)
+}
+
+func (m reportPager) footer() string {
+	percent := 100.0
+	if m.ready {
+		percent = m.viewport.ScrollPercent() * 100
+	}
+	return pagerFooterStyle.Render(fmt.Sprintf("%3.f%%  ↑/↓ pgup/pgdn scroll · q back", percent))
+}
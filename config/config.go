@@ -1,9 +1,18 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/modeledge/cleanconfig"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
 )
 
+// ConfigFileName is the file name searched for in each of the standard config locations
+const ConfigFileName = "vmuser.toml"
+
 type VMUserConfig struct {
 	Elastic      Elastic      `toml:"Elastic"`
 	Postgres     Postgres     `toml:"Database"`
@@ -11,14 +20,96 @@ type VMUserConfig struct {
 	Server       Server       `toml:"Server"`
 	LLM          LLM          `toml:"LLM"`
 	LLMLibConfig LLMLibConfig `toml:"LLMLibConfig"`
+
+	// Path is the configuration file the config was loaded from, empty if none was
+	Path string `toml:"-"`
+}
+
+// SearchPaths returns the locations searched for a config file, in order: the current directory,
+// $XDG_CONFIG_HOME/vmuser (or ~/.config/vmuser when XDG_CONFIG_HOME is unset) and /etc/vmuser.
+func SearchPaths() []string {
+	paths := []string{ConfigFileName}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+	if configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "vmuser", ConfigFileName))
+	}
+
+	return append(paths, filepath.Join("/etc", "vmuser", ConfigFileName))
 }
 
+// ErrMissingRequired is returned by LoadVMUserConfig when a required setting is empty after the file and
+// environment have been applied.
+var ErrMissingRequired = errors.New("required configuration value missing")
+
+// GetVMUserConfig loads the configuration as LoadVMUserConfig does, logging and returning the partially populated
+// config on error rather than failing.
 func GetVMUserConfig(path string) *VMUserConfig {
-	cfg, err := loadInstallerConfig(path)
-	if err == nil {
-		return cfg
+	cfg, err := LoadVMUserConfig(path)
+	if err != nil {
+		slog.Error("Error loading configuration", "error", err)
+	}
+	return cfg
+}
+
+// LoadVMUserConfig loads the configuration from path, or when path is empty from the first of SearchPaths that
+// exists. Environment variables are always applied on top of the file, and env defaults fill anything still unset, so
+// the config can be supplied entirely through the environment when no file is found. The source used is logged. An
+// error is returned if an explicitly given path is missing, a file cannot be parsed, or a required setting is empty;
+// the returned config is never nil.
+func LoadVMUserConfig(path string) (*VMUserConfig, error) {
+	candidates := SearchPaths()
+	if path != "" {
+		candidates = []string{path}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err != nil {
+			if path != "" || !errors.Is(err, fs.ErrNotExist) {
+				return &VMUserConfig{}, fmt.Errorf("error reading config file %s: %w", candidate, err)
+			}
+			continue
+		}
+
+		cfg, err := loadInstallerConfig(candidate)
+		if err != nil {
+			return &VMUserConfig{}, fmt.Errorf("error loading config file %s: %w", candidate, err)
+		}
+		cfg.Path = candidate
+		slog.Info("Loaded configuration", "source", "file", "path", candidate)
+		return cfg, cfg.Validate()
+	}
+
+	var cfg VMUserConfig
+	if err := cleanconfig.ReadEnv(&cfg); err != nil {
+		return &cfg, fmt.Errorf("error reading configuration from environment: %w", err)
+	}
+	slog.Info("Loaded configuration", "source", "environment")
+	return &cfg, cfg.Validate()
+}
+
+// Validate reports the required settings that are empty, joined into a single error wrapping ErrMissingRequired
+func (c *VMUserConfig) Validate() error {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"Turso.URL (TURSO_URL)", c.Turso.URL},
+		{"Server.Port (SERVER_PORT)", c.Server.Port},
+	}
+
+	var errs []error
+	for _, r := range required {
+		if r.value == "" {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingRequired, r.name))
+		}
 	}
-	return &VMUserConfig{}
+	return errors.Join(errs...)
 }
 
 func loadInstallerConfig(filename string) (*VMUserConfig, error) {
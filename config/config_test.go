@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadVMUserConfigEnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	content := "[Server]\nPort = \"9000\"\n\n[Turso]\nURL = \"http://file:8080\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TURSO_URL", "http://env:8080")
+
+	cfg, err := LoadVMUserConfig(path)
+	if err != nil {
+		t.Fatalf("LoadVMUserConfig: %v", err)
+	}
+	if cfg.Path != path {
+		t.Errorf("Path = %q, want %q", cfg.Path, path)
+	}
+	if cfg.Server.Port != "9000" {
+		t.Errorf("Server.Port = %q, want value from file", cfg.Server.Port)
+	}
+	if cfg.Turso.URL != "http://env:8080" {
+		t.Errorf("Turso.URL = %q, want value from env", cfg.Turso.URL)
+	}
+}
+
+func TestLoadVMUserConfigEnvOnly(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SERVER_PORT", "12345")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+
+	cfg, err := LoadVMUserConfig("")
+	if err != nil {
+		t.Fatalf("LoadVMUserConfig: %v", err)
+	}
+	if cfg.Path != "" {
+		t.Skipf("config file found at %s, environment fallback not exercised", cfg.Path)
+	}
+	if cfg.Server.Port != "12345" {
+		t.Errorf("Server.Port = %q, want 12345", cfg.Server.Port)
+	}
+	if cfg.Turso.URL != "http://localhost:8080" {
+		t.Errorf("Turso.URL = %q, want env default", cfg.Turso.URL)
+	}
+	if cfg.LLM.OpenAIKey != "sk-test" {
+		t.Errorf("LLM.OpenAIKey = %q, want sk-test", cfg.LLM.OpenAIKey)
+	}
+}
+
+func TestLoadVMUserConfigMissingExplicitPath(t *testing.T) {
+	_, err := LoadVMUserConfig(filepath.Join(t.TempDir(), "missing.toml"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("err = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestValidateMissingRequired(t *testing.T) {
+	cfg := &VMUserConfig{Server: Server{Port: "10101"}}
+	if err := cfg.Validate(); !errors.Is(err, ErrMissingRequired) {
+		t.Fatalf("Validate() = %v, want ErrMissingRequired", err)
+	}
+}
@@ -1,12 +1,12 @@
 package config
 
 type LLM struct {
-	OpenAIKey    string `toml:"OpenAIAPIKey"`
-	AnthropicKey string `toml:"AnthropicAPIKey"`
-	GeminiKey    string `toml:"GeminiAPIKey"`
+	OpenAIKey    string `toml:"OpenAIAPIKey" env:"OPENAI_API_KEY"`
+	AnthropicKey string `toml:"AnthropicAPIKey" env:"ANTHROPIC_API_KEY"`
+	GeminiKey    string `toml:"GeminiAPIKey" env:"GEMINI_API_KEY"`
 }
 
 type LLMLibConfig struct {
-	LLMLibURL    string `toml:"LLMLibURL"`
-	LLMLibAPIKey string `toml:"LLMLibAPIKey"`
+	LLMLibURL    string `toml:"LLMLibURL" env:"LLMLIB_URL"`
+	LLMLibAPIKey string `toml:"LLMLibAPIKey" env:"LLMLIB_API_KEY"`
 }
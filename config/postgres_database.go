@@ -3,8 +3,8 @@ package config
 type Postgres struct {
 	Host     string `toml:"Host" env:"DB_HOST" env-default:"localhost"`
 	Port     int    `toml:"Port" env:"DB_PORT" env-default:"5432"`
-	User     string `toml:"User" env:"DB_User"`
-	Password string `toml:"Password" env:"Password"`
-	DBName   string `toml:"DBName" env:"DBName"`
-	SSLMode  string `toml:"SSLMode" env:"SSLMode" env-default:"disable"`
+	User     string `toml:"User" env:"DB_USER,DB_User"`
+	Password string `toml:"Password" env:"DB_PASSWORD,Password"`
+	DBName   string `toml:"DBName" env:"DB_NAME,DBName"`
+	SSLMode  string `toml:"SSLMode" env:"DB_SSLMODE,SSLMode" env-default:"disable"`
 }
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces secret values in redacted configs
+const RedactedValue = "***"
+
+// redact masks a secret, leaving empty values empty so it remains visible that nothing was set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// RedactURL masks the password and query parameter values (where e.g. Turso auth tokens go) of rawURL, keeping the
+// rest intact. Values that don't parse as a URL are masked entirely.
+func RedactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return RedactedValue
+	}
+	// url.UserPassword would percent-encode the mask, so the user info is spliced back in after formatting
+	var userInfo string
+	if _, hasPassword := u.User.Password(); hasPassword {
+		userInfo = url.User(u.User.Username()).String() + ":" + RedactedValue + "@"
+		u.User = nil
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			keys[i] = url.QueryEscape(key) + "=" + RedactedValue
+		}
+		u.RawQuery = strings.Join(keys, "&")
+	}
+
+	redacted := u.String()
+	if prefix := u.Scheme + "://"; userInfo != "" && strings.HasPrefix(redacted, prefix) {
+		redacted = prefix + userInfo + redacted[len(prefix):]
+	}
+	return redacted
+}
+
+// Redacted returns a copy of the config with every secret masked, safe to print or log
+func (c *VMUserConfig) Redacted() *VMUserConfig {
+	redacted := *c
+	redacted.Elastic = c.Elastic.Redacted()
+	redacted.Postgres = c.Postgres.Redacted()
+	redacted.Turso = c.Turso.Redacted()
+	redacted.LLM = c.LLM.Redacted()
+	redacted.LLMLibConfig = c.LLMLibConfig.Redacted()
+	return &redacted
+}
+
+// LogValue implements slog.LogValuer, logging the redacted config
+func (c *VMUserConfig) LogValue() slog.Value {
+	type plain VMUserConfig
+	return slog.AnyValue(plain(*c.Redacted()))
+}
+
+// Redacted returns a copy with the password masked
+func (e Elastic) Redacted() Elastic {
+	e.Password = redact(e.Password)
+	return e
+}
+
+func (e Elastic) String() string {
+	type plain Elastic
+	return fmt.Sprintf("%+v", plain(e.Redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the redacted config
+func (e Elastic) LogValue() slog.Value {
+	type plain Elastic
+	return slog.AnyValue(plain(e.Redacted()))
+}
+
+// Redacted returns a copy with the password masked
+func (p Postgres) Redacted() Postgres {
+	p.Password = redact(p.Password)
+	return p
+}
+
+func (p Postgres) String() string {
+	type plain Postgres
+	return fmt.Sprintf("%+v", plain(p.Redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the redacted config
+func (p Postgres) LogValue() slog.Value {
+	type plain Postgres
+	return slog.AnyValue(plain(p.Redacted()))
+}
+
+// Redacted returns a copy with any credentials or auth token in the URL masked
+func (t Turso) Redacted() Turso {
+	t.URL = RedactURL(t.URL)
+	return t
+}
+
+func (t Turso) String() string {
+	type plain Turso
+	return fmt.Sprintf("%+v", plain(t.Redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the redacted config
+func (t Turso) LogValue() slog.Value {
+	type plain Turso
+	return slog.AnyValue(plain(t.Redacted()))
+}
+
+// Redacted returns a copy with every API key masked
+func (l LLM) Redacted() LLM {
+	l.OpenAIKey = redact(l.OpenAIKey)
+	l.AnthropicKey = redact(l.AnthropicKey)
+	l.GeminiKey = redact(l.GeminiKey)
+	return l
+}
+
+func (l LLM) String() string {
+	type plain LLM
+	return fmt.Sprintf("%+v", plain(l.Redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the redacted config
+func (l LLM) LogValue() slog.Value {
+	type plain LLM
+	return slog.AnyValue(plain(l.Redacted()))
+}
+
+// Redacted returns a copy with the API key, and any credentials in the URL, masked
+func (l LLMLibConfig) Redacted() LLMLibConfig {
+	l.LLMLibURL = RedactURL(l.LLMLibURL)
+	l.LLMLibAPIKey = redact(l.LLMLibAPIKey)
+	return l
+}
+
+func (l LLMLibConfig) String() string {
+	type plain LLMLibConfig
+	return fmt.Sprintf("%+v", plain(l.Redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the redacted config
+func (l LLMLibConfig) LogValue() slog.Value {
+	type plain LLMLibConfig
+	return slog.AnyValue(plain(l.Redacted()))
+}
@@ -1,5 +1,7 @@
 package config
 
 type Server struct {
-	Port string `toml:"Port" env:"SERVER_PORT" env-default:"10101"`
+	Port         string `toml:"Port" env:"SERVER_PORT" env-default:"10101"`
+	MaxBodyBytes int64  `toml:"MaxBodyBytes" env:"SERVER_MAX_BODY_BYTES" env-default:"10485760"`
+	Metrics      bool   `toml:"Metrics" env:"SERVER_METRICS" env-default:"false"`
 }
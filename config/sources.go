@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Source records where the effective value of a setting came from
+type Source string
+
+const (
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceDefault Source = "default"
+	SourceUnset   Source = "unset"
+)
+
+// Field is a single effective setting, with secrets already redacted
+type Field struct {
+	Section string   // TOML table, e.g. "Turso"
+	Key     string   // TOML key within the table, e.g. "URL"
+	Env     []string // environment variables that set the field, if any
+	Value   any
+	Source  Source
+}
+
+// Fields lists every setting in the config with its redacted value and source, in declaration order. A value is
+// attributed to the environment if one of its env variables is set, since those override the file; otherwise to the
+// file at c.Path if it defines the key, then to the field's env default. Fields matching none are unset.
+func (c *VMUserConfig) Fields() ([]Field, error) {
+	var fileKeys toml.MetaData
+	if c.Path != "" {
+		var raw map[string]any
+		md, err := toml.DecodeFile(c.Path, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", c.Path, err)
+		}
+		fileKeys = md
+	}
+
+	var fields []Field
+	cfg := reflect.ValueOf(c.Redacted()).Elem()
+	for i := 0; i < cfg.NumField(); i++ {
+		sectionField := cfg.Type().Field(i)
+		section := sectionField.Tag.Get("toml")
+		if section == "" || section == "-" || sectionField.Type.Kind() != reflect.Struct {
+			continue
+		}
+
+		sectionValue := cfg.Field(i)
+		for j := 0; j < sectionValue.NumField(); j++ {
+			structField := sectionValue.Type().Field(j)
+			field := Field{
+				Section: section,
+				Key:     structField.Tag.Get("toml"),
+				Value:   sectionValue.Field(j).Interface(),
+				Source:  SourceUnset,
+			}
+			if env := structField.Tag.Get("env"); env != "" {
+				field.Env = strings.Split(env, ",")
+			}
+
+			_, hasDefault := structField.Tag.Lookup("env-default")
+			switch {
+			case envIsSet(field.Env):
+				field.Source = SourceEnv
+			case fileKeys.IsDefined(field.Section, field.Key):
+				field.Source = SourceFile
+			case hasDefault:
+				field.Source = SourceDefault
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+func envIsSet(names []string) bool {
+	for _, name := range names {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFieldsSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	content := "[Turso]\nURL = \"libsql://db.turso.io?authToken=secret\"\n\n[Server]\nPort = \"9000\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SERVER_PORT", "9100")
+	// t.Setenv restores the original value once the test ends
+	t.Setenv("GEMINI_API_KEY", "")
+	os.Unsetenv("GEMINI_API_KEY")
+
+	cfg, err := LoadVMUserConfig(path)
+	if err != nil {
+		t.Fatalf("LoadVMUserConfig: %v", err)
+	}
+	fields, err := cfg.Fields()
+	if err != nil {
+		t.Fatalf("Fields: %v", err)
+	}
+
+	bySetting := make(map[string]Field)
+	for _, f := range fields {
+		bySetting[f.Section+"."+f.Key] = f
+	}
+
+	tests := []struct {
+		setting string
+		source  Source
+		value   any
+	}{
+		{"Turso.URL", SourceFile, "libsql://db.turso.io?authToken=***"},
+		{"Server.Port", SourceEnv, "9100"},
+		{"Turso.DBName", SourceDefault, "turso"},
+		{"LLM.GeminiAPIKey", SourceUnset, ""},
+	}
+	for _, tt := range tests {
+		f, ok := bySetting[tt.setting]
+		if !ok {
+			t.Errorf("%s missing from Fields", tt.setting)
+			continue
+		}
+		if f.Source != tt.source || f.Value != tt.value {
+			t.Errorf("%s = %v from %s, want %v from %s", tt.setting, f.Value, f.Source, tt.value, tt.source)
+		}
+	}
+}
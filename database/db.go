@@ -1,9 +1,12 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
+	"log/slog"
+	"time"
 	"vmuser/config"
 )
 
@@ -14,3 +17,35 @@ func GetConnection(cfg *config.Turso) (*sql.DB, error) {
 	}
 	return db, nil
 }
+
+// GetConnectionWithRetry opens a connection like GetConnection and pings the database, retrying up to attempts times
+// in all until it responds, so that an app started before its database is reachable does not fail at once. As in the
+// HTTP retry layer, the wait before retry i (from 0) is backoff doubled i times. It stops early if ctx is done.
+func GetConnectionWithRetry(ctx context.Context, cfg *config.Turso, attempts int, backoff time.Duration) (*sql.DB, error) {
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			wait := backoff * time.Duration(1<<(i-1))
+			slog.Warn("Database not reachable, retrying after backoff",
+				"attempt", i, "maxAttempts", attempts, "backoffDuration", wait, "lastError", lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%w while waiting for database: %w", ctx.Err(), lastErr)
+			case <-time.After(wait):
+			}
+		}
+
+		db, err := GetConnection(cfg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := db.PingContext(ctx); err != nil {
+			db.Close()
+			lastErr = fmt.Errorf("error pinging database: %w", err)
+			continue
+		}
+		return db, nil
+	}
+	return nil, fmt.Errorf("database not reachable after %d attempts: %w", attempts, lastErr)
+}
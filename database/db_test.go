@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"vmuser/config"
+)
+
+func TestGetConnectionWithRetry(t *testing.T) {
+	db, err := GetConnectionWithRetry(context.Background(), &config.Turso{URL: testDSN}, 3, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// A read-only database that does not exist cannot be opened
+	missing := &config.Turso{URL: "file:" + filepath.Join(t.TempDir(), "missing.db") + "?mode=ro"}
+	start := time.Now()
+	if _, err := GetConnectionWithRetry(context.Background(), missing, 3, 10*time.Millisecond); err == nil {
+		t.Fatal("connecting to a missing database succeeded")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("gave up after %v, want backoffs of 10ms and 20ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := GetConnectionWithRetry(ctx, missing, 10, time.Hour); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"vmuser/pkg/reports"
+)
+
+// testDSN opens a private in-memory database. The libsql driver hands file: URLs to the sqlite3 driver imported
+// above.
+const testDSN = "file::memory:"
+
+// getTestConnection is dbtest.GetTestConnection for the tests of this package, which dbtest cannot be imported into
+// since it imports this package
+func getTestConnection() (*sql.DB, error) {
+	db, err := sql.Open("libsql", testDSN)
+	if err != nil {
+		return nil, fmt.Errorf("error opening test database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if err := NewTursoFileSystemFromDB(db).initialize(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating virtual filesystem schema: %w", err)
+	}
+	if err := reports.EnsureSchema(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,41 @@
+// Package dbtest opens in-memory SQLite databases for tests of packages that use the database package. It links the
+// cgo go-sqlite3 driver, so it must only be imported from tests; production builds use the libsql driver alone.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"strings"
+	"vmuser/database"
+)
+
+// TestDSN opens a private in-memory database. The libsql driver hands file: URLs to the sqlite3 driver imported
+// above.
+const TestDSN = "file::memory:"
+
+// GetTestConnection opens an empty in-memory database with the virtual filesystem and reports schemas applied, so
+// tests of both can run hermetically without a libsql server. Every call returns a separate database, which exists for
+// as long as its single connection: the pool is limited to one connection since a new one would see a new, empty
+// database. Close it when done.
+func GetTestConnection() (*sql.DB, error) {
+	db, err := sql.Open("libsql", TestDSN)
+	if err != nil {
+		return nil, fmt.Errorf("error opening test database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	problems, err := database.Repair(context.Background(), db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating test database schema: %w", err)
+	}
+	if len(problems) > 0 {
+		db.Close()
+		return nil, fmt.Errorf("test database schema is incomplete: %s", strings.Join(problems, "; "))
+	}
+	return db, nil
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrLocked is returned by AcquireLock when another owner holds an unexpired lease on the path.
+	ErrLocked = errors.New("file is locked by another owner")
+	// ErrInvalidLease is returned when a lease ID does not match an unexpired lease on the path.
+	ErrInvalidLease = errors.New("lease is not held or has expired")
+)
+
+// AcquireLock claims an advisory lock on path for owner, valid for ttl, and returns the lease ID to pass to
+// UpdateFileWithLease and ReleaseLock. Locks are advisory: only writers using leases honour them. An expired lease
+// can be claimed by anyone; an owner acquiring a path it already holds renews the lock under a new lease ID. If
+// another owner holds an unexpired lease, ErrLocked is returned.
+func (fs *TursoFileSystem) AcquireLock(path, owner string, ttl time.Duration) (string, error) {
+	return fs.AcquireLockContext(context.Background(), path, owner, ttl)
+}
+
+// AcquireLockContext is like AcquireLock but runs its queries under ctx.
+func (fs *TursoFileSystem) AcquireLockContext(ctx context.Context, path, owner string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.New("lock ttl must be positive")
+	}
+
+	now := time.Now()
+	leaseID := generateUUID()
+
+	// The conflict update only applies when the existing lease is expired or belongs to the same owner, so the
+	// check and the claim happen in a single statement
+	result, err := fs.db.ExecContext(ctx, `
+		INSERT INTO file_locks (path, lease_id, owner, expires_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			lease_id = excluded.lease_id,
+			owner = excluded.owner,
+			expires_at = excluded.expires_at
+		WHERE file_locks.expires_at <= ? OR file_locks.owner = excluded.owner
+	`, path, leaseID, owner, now.Add(ttl).UnixNano(), now.UnixNano())
+
+	if err != nil {
+		return "", fmt.Errorf("lock acquisition failed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("error checking lock result: %w", err)
+	}
+	if rows == 0 {
+		return "", fmt.Errorf("%w: %s", ErrLocked, path)
+	}
+
+	return leaseID, nil
+}
+
+// ReleaseLock releases the lease on path. It returns ErrInvalidLease if leaseID does not hold the lock, for example
+// because it expired and was claimed by another owner.
+func (fs *TursoFileSystem) ReleaseLock(path, leaseID string) error {
+	return fs.ReleaseLockContext(context.Background(), path, leaseID)
+}
+
+// ReleaseLockContext is like ReleaseLock but runs its queries under ctx.
+func (fs *TursoFileSystem) ReleaseLockContext(ctx context.Context, path, leaseID string) error {
+	result, err := fs.db.ExecContext(ctx, `
+		DELETE FROM file_locks
+		WHERE path = ? AND lease_id = ?
+	`, path, leaseID)
+
+	if err != nil {
+		return fmt.Errorf("lock release failed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking release result: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidLease, path)
+	}
+
+	return nil
+}
+
+// UpdateFileWithLease modifies an existing file's content like UpdateFile, but only while leaseID holds an unexpired
+// lock on path. Otherwise it returns ErrInvalidLease and leaves the file untouched. Symbolic links and directories are
+// rejected as by UpdateFile.
+func (fs *TursoFileSystem) UpdateFileWithLease(path, leaseID string, content []byte) error {
+	return fs.UpdateFileWithLeaseContext(context.Background(), path, leaseID, content)
+}
+
+// UpdateFileWithLeaseContext is like UpdateFileWithLease but runs its queries under ctx.
+func (fs *TursoFileSystem) UpdateFileWithLeaseContext(ctx context.Context, path, leaseID string, content []byte) error {
+	if err := fs.checkMimeType(path, content); err != nil {
+		return err
+	}
+
+	stored, encoding, err := fs.encodeContent(content)
+	if err != nil {
+		return err
+	}
+
+	result, err := fs.db.ExecContext(ctx, `
+		UPDATE virtual_filesystem
+		SET content = ?, metadata = json_patch(metadata, ?), updated_at = CURRENT_TIMESTAMP
+		WHERE path = ? AND `+writableSQL+` AND EXISTS (
+			SELECT 1 FROM file_locks
+			WHERE file_locks.path = ? AND lease_id = ? AND expires_at > ?
+		)
+	`, stored, encodingPatch(encoding, len(content)), path, path, leaseID, time.Now().UnixNano())
+
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking update result: %w", err)
+	}
+	if rows > 0 {
+		fs.publish(FileUpdated, path)
+		return nil
+	}
+
+	// Nothing was updated: tell a missing or unwritable file apart from a missing lease
+	if err := fs.unwritableErr(ctx, path); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidLease, path)
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFileLockLeases(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/shared.txt", []byte("v0"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err := fs.AcquireLock("/shared.txt", "alice", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.AcquireLock("/shared.txt", "bob", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Fatalf("acquire of a held lock by another owner = %v, want ErrLocked", err)
+	}
+	if err := fs.UpdateFileWithLease("/shared.txt", lease, []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Renewing by the same owner replaces the lease
+	renewed, err := fs.AcquireLock("/shared.txt", "alice", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed == lease {
+		t.Fatal("renewal returned the old lease ID")
+	}
+	if err := fs.UpdateFileWithLease("/shared.txt", lease, []byte("v2")); !errors.Is(err, ErrInvalidLease) {
+		t.Fatalf("update with the replaced lease = %v, want ErrInvalidLease", err)
+	}
+	if err := fs.ReleaseLock("/shared.txt", renewed); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.ReleaseLock("/shared.txt", renewed); !errors.Is(err, ErrInvalidLease) {
+		t.Fatalf("second release = %v, want ErrInvalidLease", err)
+	}
+
+	// Once a lease expires another owner can take the lock over, and the expired lease no longer works
+	expiring, err := fs.AcquireLock("/shared.txt", "alice", 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := fs.UpdateFileWithLease("/shared.txt", expiring, []byte("v3")); !errors.Is(err, ErrInvalidLease) {
+		t.Fatalf("update with an expired lease = %v, want ErrInvalidLease", err)
+	}
+	taken, err := fs.AcquireLock("/shared.txt", "bob", time.Minute)
+	if err != nil {
+		t.Fatalf("take over of an expired lock: %v", err)
+	}
+	if err := fs.ReleaseLock("/shared.txt", expiring); !errors.Is(err, ErrInvalidLease) {
+		t.Fatalf("release of a taken over lease = %v, want ErrInvalidLease", err)
+	}
+	if err := fs.UpdateFileWithLease("/shared.txt", taken, []byte("v4")); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := fs.ReadFile("/shared.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(file.Content) != "v4" {
+		t.Errorf("content = %q, want %q", file.Content, "v4")
+	}
+}
@@ -4,4 +4,5 @@ const (
 	MaxFileSize          = 10 * 1024 * 1024 // 10MB max file size
 	MaxFilesPerDirectory = 1000             // Prevent directory bombs
 	MaxPathLength        = 256              // Reasonable path length limit
+	MaxSymlinkDepth      = 8                // Links followed before ReadFile reports a loop
 )
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OperationLogEntry is a single row of the operation_log table
+type OperationLogEntry struct {
+	ID        int64     `json:"id"`
+	Operation string    `json:"operation"`
+	Details   string    `json:"details"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// LatestOperationID returns the id of the most recent operation log entry, or 0 if the log is empty. A database whose
+// operation log has not been created yet counts as empty.
+func LatestOperationID(ctx context.Context, db *sql.DB) (int64, error) {
+	var id sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT MAX(id) FROM operation_log`).Scan(&id)
+	if isNoSuchTableErr(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting latest operation id: %w", err)
+	}
+	return id.Int64, nil
+}
+
+// OperationsSince returns the operation log entries with an id greater than afterID, oldest first. Like
+// LatestOperationID, it treats a missing operation log as empty, so the log can be followed before it is created.
+func OperationsSince(ctx context.Context, db *sql.DB, afterID int64) ([]OperationLogEntry, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, operation, details, timestamp
+		FROM operation_log
+		WHERE id > ?
+		ORDER BY id ASC
+	`, afterID)
+	if isNoSuchTableErr(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []OperationLogEntry
+	for rows.Next() {
+		var entry OperationLogEntry
+		var details sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.Operation, &details, &entry.Timestamp); err != nil {
+			return nil, fmt.Errorf("row scan failed: %w", err)
+		}
+		entry.Details = details.String
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating operation log rows: %w", err)
+	}
+
+	return entries, nil
+}
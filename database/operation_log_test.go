@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOperationLogMissingTable(t *testing.T) {
+	db, err := getTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	// A database no operation has been logged to by this version yet
+	if _, err := db.Exec(`DROP TABLE operation_log`); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := LatestOperationID(ctx, db)
+	if err != nil || id != 0 {
+		t.Fatalf("LatestOperationID = (%d, %v), want (0, nil)", id, err)
+	}
+	entries, err := OperationsSince(ctx, db, 0)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("OperationsSince = (%v, %v), want no entries", entries, err)
+	}
+
+	// Once the table is created, its entries are seen
+	if err := NewTursoFileSystemFromDB(db).initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO operation_log (operation, details) VALUES ('write', '/a.txt')`); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = OperationsSince(ctx, db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Operation != "write" {
+		t.Errorf("entries = %+v, want the write", entries)
+	}
+}
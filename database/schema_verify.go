@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"vmuser/pkg/reports"
+)
+
+// expectedTables lists the columns every table should have once all schemas and migrations are applied. The reports
+// table is owned by pkg/reports and only created when the first report is written.
+var expectedTables = []struct {
+	name    string
+	columns []string
+}{
+	{"system_config", []string{"key", "value", "updated_at"}},
+	{"virtual_filesystem", []string{"id", "path", "content", "metadata", "created_at", "updated_at", "mime_type"}},
+	{"operation_log", []string{"id", "operation", "details", "timestamp"}},
+	{"file_locks", []string{"path", "lease_id", "owner", "expires_at"}},
+	{"file_tags", []string{"file_id", "tag"}},
+	{"reports", []string{"id", "content", "filename", "content_hash", "created_at", "updated_at"}},
+}
+
+// expectedIndexes and expectedTriggers list the indexes and triggers created by the schemas
+var expectedIndexes = []string{"idx_vfs_path", "idx_vfs_mime_type", "idx_file_tags_tag", "idx_reports_content_hash"}
+
+var expectedTriggers = []string{"vfs_tags_insert", "vfs_tags_update", "vfs_tags_delete"}
+
+// Verify checks that db has every table, column, index and trigger of the current schema, and returns a description of
+// each one that is missing. Since schemas are applied with CREATE ... IF NOT EXISTS, a database created by an older
+// version can lack later additions without anything failing until a query that relies on them is slow or fails.
+// Verify changes nothing; see Repair.
+func Verify(ctx context.Context, db *sql.DB) ([]string, error) {
+	var problems []string
+
+	for _, table := range expectedTables {
+		columns, err := tableColumns(ctx, db, table.name)
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			problems = append(problems, fmt.Sprintf("table %s is missing", table.name))
+			continue
+		}
+		for _, column := range table.columns {
+			if !columns[column] {
+				problems = append(problems, fmt.Sprintf("column %s.%s is missing", table.name, column))
+			}
+		}
+	}
+
+	for _, expected := range []struct {
+		kind  string
+		names []string
+	}{{"index", expectedIndexes}, {"trigger", expectedTriggers}} {
+		for _, name := range expected.names {
+			var count int
+			err := db.QueryRowContext(ctx, `
+				SELECT COUNT(*) FROM sqlite_master WHERE type = ? AND name = ?
+			`, expected.kind, name).Scan(&count)
+			if err != nil {
+				return nil, fmt.Errorf("error inspecting schema: %w", err)
+			}
+			if count == 0 {
+				problems = append(problems, fmt.Sprintf("%s %s is missing", expected.kind, name))
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// Repair applies the current schema and its migrations to db, creating whatever Verify reports missing, and returns
+// what Verify still reports afterwards. Existing data is kept; tags are backfilled into file_tags if it is created.
+func Repair(ctx context.Context, db *sql.DB) ([]string, error) {
+	if err := NewTursoFileSystemFromDB(db).initialize(); err != nil {
+		return nil, fmt.Errorf("error applying virtual filesystem schema: %w", err)
+	}
+	if err := reports.EnsureSchema(ctx, db); err != nil {
+		return nil, err
+	}
+	return Verify(ctx, db)
+}
+
+// tableColumns returns the set of columns of table, including generated ones, or an empty set if it does not exist
+func tableColumns(ctx context.Context, db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_table_xinfo(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("error inspecting table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error inspecting table %s: %w", table, err)
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error inspecting table %s: %w", table, err)
+	}
+	return columns, nil
+}
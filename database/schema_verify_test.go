@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestVerifyAndRepair(t *testing.T) {
+	ctx := context.Background()
+	db, err := getTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	problems, err := Verify(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("Verify of a current database = %v, want none", problems)
+	}
+
+	// Simulate a database from before the metadata indexes
+	for _, stmt := range []string{
+		`DROP INDEX idx_vfs_path`,
+		`DROP TRIGGER vfs_tags_update`,
+		`DROP TABLE file_tags`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems, err = Verify(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"table file_tags is missing",
+		"index idx_vfs_path is missing",
+		"index idx_file_tags_tag is missing",
+		"trigger vfs_tags_update is missing",
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("Verify = %q, want %q", problems, want)
+	}
+
+	problems, err = Repair(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Verify after Repair = %v, want none", problems)
+	}
+}
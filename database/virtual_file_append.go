@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// AppendToFile appends data to the file at path, creating it with data as its content if it does not exist. Unlike a
+// ReadFile followed by UpdateFile, concurrent appends are never lost and only data is sent to the database: the
+// content is extended in place by a single UPDATE. Files stored compressed (see WithAtRestCompression) are instead
+// decompressed, extended and stored again, in the same transaction, as are all files when a MIME type policy is
+// configured, since the policy is checked against the resulting content. The resulting file must not exceed
+// MaxFileSize. Like UpdateFile, it fails with ErrIsSymlink or ErrIsDirectory if path is a symbolic link or directory.
+func (fs *TursoFileSystem) AppendToFile(path string, data []byte) error {
+	return fs.AppendToFileContext(context.Background(), path, data)
+}
+
+// AppendToFileContext is like AppendToFile but runs its queries under ctx.
+func (fs *TursoFileSystem) AppendToFileContext(ctx context.Context, path string, data []byte) error {
+	tx, err := fs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var rows int64
+	if fs.allowedMimeTypes == nil && fs.deniedMimeTypes == nil {
+		// || works on text, so the result is cast back to a BLOB to keep the content's bytes as they are
+		result, err := tx.ExecContext(ctx, `
+			UPDATE virtual_filesystem
+			SET content = CAST(COALESCE(content, X'') || ? AS BLOB), updated_at = CURRENT_TIMESTAMP
+			WHERE path = ? AND `+writableSQL+`
+				AND json_extract(metadata, '$.content_encoding') IS NULL
+				AND LENGTH(content) + ? <= ?
+		`, data, path, len(data), MaxFileSize)
+		if err != nil {
+			return fmt.Errorf("append failed: %w", err)
+		}
+		rows, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("error checking append result: %w", err)
+		}
+	}
+
+	op := FileUpdated
+	if rows == 0 {
+		// The file is missing, compressed, would grow too large, is not writable or must pass the MIME type policy
+		op, err = fs.appendSlowPath(ctx, tx, path, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing append: %w", err)
+	}
+
+	fs.publish(op, path)
+	return nil
+}
+
+// appendSlowPath appends data to the file at path within tx when it cannot be done by extending the stored content,
+// and returns whether the file was created or updated
+func (fs *TursoFileSystem) appendSlowPath(ctx context.Context, tx *sql.Tx, path string, data []byte) (FileOperation, error) {
+	file := VirtualFile{Path: path}
+	var metadataStr string
+	err := tx.QueryRowContext(ctx, `
+		SELECT content, metadata FROM virtual_filesystem WHERE path = ?
+	`, path).Scan(&file.Content, &metadataStr)
+
+	if err == sql.ErrNoRows {
+		metadata := Metadata{
+			MimeType:    fs.DetectMimeType(path, data),
+			Tags:        []string{},
+			Permissions: map[string]string{"access": "rw"},
+		}
+		stored, metadataJSON, err := fs.prepareNewFile(path, data, metadata)
+		if err != nil {
+			return "", err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO virtual_filesystem (id, path, content, metadata)
+			VALUES (?, ?, ?, ?)
+		`, generateUUID(), path, stored, metadataJSON)
+		if err != nil {
+			return "", fmt.Errorf("append failed: %w", err)
+		}
+		return FileCreated, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("database error: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
+		return "", fmt.Errorf("metadata parse error: %w", err)
+	}
+	if err := writeRejection(file.Metadata.MimeType, path); err != nil {
+		return "", err
+	}
+	if err := decodeContent(&file); err != nil {
+		return "", err
+	}
+
+	content := append(file.Content, data...)
+	if len(content) > MaxFileSize {
+		return "", fmt.Errorf("file exceeds maximum size of %d bytes", MaxFileSize)
+	}
+	if err := fs.checkMimeType(path, content); err != nil {
+		return "", err
+	}
+	stored, encoding, err := fs.encodeContent(content)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE virtual_filesystem
+		SET content = ?, metadata = json_patch(metadata, ?), updated_at = CURRENT_TIMESTAMP
+		WHERE path = ?
+	`, stored, encodingPatch(encoding, len(content)), path)
+	if err != nil {
+		return "", fmt.Errorf("append failed: %w", err)
+	}
+	return FileUpdated, nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAppendToFile(t *testing.T) {
+	for _, threshold := range []int{0, 16} {
+		t.Run(fmt.Sprintf("compression threshold %d", threshold), func(t *testing.T) {
+			fs := newTestFileSystem(t)
+			fs.compressionThreshold = threshold
+
+			want := []byte(strings.Repeat("first line\n", 20))
+			if err := fs.AppendToFile("/logs/agent.log", want); err != nil {
+				t.Fatal(err)
+			}
+			binary := []byte{0, 0xff, 'x', 0, '\n'}
+			for _, data := range [][]byte{[]byte("second line\n"), binary} {
+				if err := fs.AppendToFile("/logs/agent.log", data); err != nil {
+					t.Fatal(err)
+				}
+				want = append(want, data...)
+			}
+
+			var encoding sql.NullString
+			err := fs.db.QueryRow(`SELECT json_extract(metadata, '$.content_encoding') FROM virtual_filesystem`).Scan(&encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if encoding.Valid != (threshold > 0) {
+				t.Errorf("stored encoding = %v with compression threshold %d", encoding, threshold)
+			}
+
+			file, err := fs.ReadFile("/logs/agent.log")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(file.Content, want) {
+				t.Errorf("content = %q, want %q", file.Content, want)
+			}
+			if file.Metadata.MimeType != "text/plain" {
+				t.Errorf("MimeType = %q, want text/plain", file.Metadata.MimeType)
+			}
+		})
+	}
+}
+
+func TestAppendToFileConcurrent(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/transcript.txt", nil, Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fs.AppendToFile("/transcript.txt", []byte("line\n")); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	content, err := fs.ReadFileString("/transcript.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(content, "line\n"); got != 20 {
+		t.Errorf("got %d lines, want 20", got)
+	}
+}
+
+func TestAppendToFileMaxSize(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/big.bin", make([]byte, MaxFileSize-1), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.AppendToFile("/big.bin", []byte("ab")); err == nil {
+		t.Fatal("append past MaxFileSize succeeded")
+	}
+	if err := fs.AppendToFile("/big.bin", []byte("a")); err != nil {
+		t.Fatalf("append up to MaxFileSize: %v", err)
+	}
+	info, err := fs.StatFile("/big.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != MaxFileSize {
+		t.Errorf("Size = %d, want %d", info.Size, MaxFileSize)
+	}
+}
+
+func TestAppendToFileRejections(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/a.txt", []byte("target"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateSymlink("/l", "/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateDirectory("/docs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateFile("/tool", nil, Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.AppendToFile("/l", []byte("X")); !errors.Is(err, ErrIsSymlink) {
+		t.Errorf("append to a link = %v, want ErrIsSymlink", err)
+	}
+	if target, err := fs.ReadLink("/l"); err != nil || target != "/a.txt" {
+		t.Errorf("link target after append = (%q, %v), want /a.txt", target, err)
+	}
+	if err := fs.AppendToFile("/docs/", []byte("X")); !errors.Is(err, ErrIsDirectory) {
+		t.Errorf("append to a directory = %v, want ErrIsDirectory", err)
+	}
+
+	// An empty file cannot be turned into a denied executable by appending to it
+	WithDeniedMimeTypes(ExecutableMimeTypes...)(fs)
+	if err := fs.AppendToFile("/tool", []byte("\x7fELF\x02\x01\x01\x00")); !errors.Is(err, ErrMimeTypeNotAllowed) {
+		t.Errorf("append of an executable = %v, want ErrMimeTypeNotAllowed", err)
+	}
+	file, err := fs.ReadFile("/tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Content) != 0 {
+		t.Errorf("content after a rejected append = %q, want it empty", file.Content)
+	}
+}
@@ -0,0 +1,273 @@
+package database
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// PAX records carrying the virtual file fields that have no tar header equivalent
+const (
+	paxMetadataRecord  = "VMUSER.metadata"
+	paxCreatedAtRecord = "VMUSER.created_at"
+)
+
+// ExportTar writes every file, directory and symbolic link of the virtual filesystem to w as a PAX tar archive. Each
+// entry's metadata and creation time are stored as PAX records, so ImportTar restores them unchanged.
+func (fs *TursoFileSystem) ExportTar(w io.Writer) error {
+	return fs.ExportTarContext(context.Background(), w)
+}
+
+// ExportTarContext is like ExportTar but runs its queries under ctx.
+func (fs *TursoFileSystem) ExportTarContext(ctx context.Context, w io.Writer) error {
+	rows, err := fs.db.QueryContext(ctx, `
+		SELECT id, path, content, metadata, created_at, updated_at
+		FROM virtual_filesystem
+		ORDER BY path
+	`)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	tw := tar.NewWriter(w)
+	for rows.Next() {
+		var file VirtualFile
+		var metadataStr string
+
+		err := rows.Scan(
+			&file.ID,
+			&file.Path,
+			&file.Content,
+			&metadataStr,
+			&file.CreatedAt,
+			&file.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("row scan failed: %w", err)
+		}
+		if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
+			return fmt.Errorf("metadata parse error for %s: %w", file.Path, err)
+		}
+		// Archives hold original content, so they can be imported whatever the compression settings
+		if err := decodeContent(&file); err != nil {
+			return err
+		}
+		markSymlink(&file)
+
+		metadataJSON, err := json.Marshal(file.Metadata)
+		if err != nil {
+			return fmt.Errorf("metadata marshaling failed for %s: %w", file.Path, err)
+		}
+		if err := writeTarEntry(tw, &file, string(metadataJSON)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating files: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error finishing tar archive: %w", err)
+	}
+	return nil
+}
+
+// writeTarEntry writes a single virtual file as a tar header followed by its content
+func writeTarEntry(tw *tar.Writer, file *VirtualFile, metadataJSON string) error {
+	hdr := &tar.Header{
+		Name:    file.Path,
+		Mode:    0o644,
+		ModTime: file.UpdatedAt,
+		Format:  tar.FormatPAX,
+		PAXRecords: map[string]string{
+			paxMetadataRecord:  metadataJSON,
+			paxCreatedAtRecord: file.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}
+
+	switch {
+	case file.IsSymlink():
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = file.LinkTarget
+		hdr.Mode = 0o777
+	case strings.HasSuffix(file.Path, "/"):
+		hdr.Typeflag = tar.TypeDir
+		hdr.Mode = 0o755
+	default:
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = int64(len(file.Content))
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("error writing tar header for %s: %w", file.Path, err)
+	}
+	if hdr.Typeflag == tar.TypeReg {
+		if _, err := tw.Write(file.Content); err != nil {
+			return fmt.Errorf("error writing tar content for %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// ImportTar recreates the files, directories and symbolic links of a tar archive written by ExportTar in a single
+// transaction: either every entry is imported or none is. Entries replace existing files at the same path. Archives
+// from other sources are accepted too; entries without stored metadata get the metadata a newly written file would.
+// A regular file rejected by the MIME type policy (see WithDeniedMimeTypes) fails the whole import.
+func (fs *TursoFileSystem) ImportTar(r io.Reader) error {
+	return fs.ImportTarContext(context.Background(), r)
+}
+
+// ImportTarContext is like ImportTar but runs its queries under ctx.
+func (fs *TursoFileSystem) ImportTarContext(ctx context.Context, r io.Reader) error {
+	tx, err := fs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The id returned is the new one only if the row was inserted
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO virtual_filesystem (id, path, content, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			content = excluded.content,
+			metadata = excluded.metadata,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+		RETURNING id
+	`)
+	if err != nil {
+		return fmt.Errorf("error preparing file import: %w", err)
+	}
+	defer stmt.Close()
+
+	var created, updated []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar archive: %w", err)
+		}
+
+		path, content, metadata, err := fs.readTarEntry(tr, hdr)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			continue
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			// Imported files are subject to the same MIME type policy as files written one by one
+			if err := fs.checkMimeType(path, content); err != nil {
+				return err
+			}
+			stored, encoding, err := fs.encodeContent(content)
+			if err != nil {
+				return err
+			}
+			if encoding != "" {
+				content = stored
+				metadata.ContentEncoding = encoding
+				metadata.UncompressedSize = hdr.Size
+			}
+		}
+		metadataJSON, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("metadata marshaling failed for %s: %w", path, err)
+		}
+
+		createdAt := hdr.ModTime
+		if created, ok := hdr.PAXRecords[paxCreatedAtRecord]; ok {
+			if t, err := time.Parse(time.RFC3339Nano, created); err == nil {
+				createdAt = t
+			}
+		}
+
+		id := generateUUID()
+		var storedID string
+		err = stmt.QueryRowContext(ctx, id, path, content, string(metadataJSON), createdAt.UTC(), hdr.ModTime.UTC()).
+			Scan(&storedID)
+		if err != nil {
+			return fmt.Errorf("error importing %s: %w", path, err)
+		}
+		if storedID == id {
+			created = append(created, path)
+		} else {
+			updated = append(updated, path)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing import: %w", err)
+	}
+
+	fs.publish(FileCreated, created...)
+	fs.publish(FileUpdated, updated...)
+	return nil
+}
+
+// readTarEntry returns the path, original content and metadata of a tar entry. Entry types with no virtual filesystem
+// equivalent (e.g. devices) are skipped by returning an empty path.
+func (fs *TursoFileSystem) readTarEntry(tr *tar.Reader, hdr *tar.Header) (string, []byte, Metadata, error) {
+	path := hdr.Name
+	if len(path) > MaxPathLength {
+		return "", nil, Metadata{}, fmt.Errorf("path exceeds maximum length of %d characters: %s", MaxPathLength, path)
+	}
+
+	var content []byte
+	var metadata Metadata
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		if hdr.Size > MaxFileSize {
+			return "", nil, Metadata{}, fmt.Errorf("file exceeds maximum size of %d bytes: %s", MaxFileSize, path)
+		}
+		var err error
+		content, err = io.ReadAll(tr)
+		if err != nil {
+			return "", nil, Metadata{}, fmt.Errorf("error reading tar content for %s: %w", path, err)
+		}
+		metadata = Metadata{
+			MimeType:    fs.DetectMimeType(path, content),
+			Tags:        []string{},
+			Permissions: map[string]string{"access": "rw"},
+		}
+	case tar.TypeDir:
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+		metadata = Metadata{
+			MimeType:    "directory",
+			Tags:        []string{"directory"},
+			Permissions: map[string]string{"type": "directory"},
+		}
+	case tar.TypeSymlink:
+		content = []byte(hdr.Linkname)
+		metadata = Metadata{
+			MimeType:    SymlinkMimeType,
+			Tags:        []string{"symlink"},
+			Permissions: map[string]string{"type": "symlink"},
+		}
+	default:
+		return "", nil, Metadata{}, nil
+	}
+
+	if stored, ok := hdr.PAXRecords[paxMetadataRecord]; ok {
+		metadata = Metadata{}
+		if err := json.Unmarshal([]byte(stored), &metadata); err != nil {
+			return "", nil, Metadata{}, fmt.Errorf("invalid metadata for %s: %w", path, err)
+		}
+		clearStorageMetadata(&metadata)
+	}
+
+	return path, content, metadata, nil
+}
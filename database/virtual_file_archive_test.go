@@ -0,0 +1,109 @@
+package database
+
+import (
+	"archive/tar"
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTarRoundTrip(t *testing.T) {
+	source := newTestFileSystem(t)
+	metadata := Metadata{
+		MimeType:    "text/markdown",
+		Tags:        []string{"notes", "draft"},
+		Permissions: map[string]string{"access": "r"},
+	}
+	if err := source.CreateFile("/docs/a.md", []byte("# Notes"), metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CreateDirectory("/docs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CreateSymlink("/latest", "/docs/a.md"); err != nil {
+		t.Fatal(err)
+	}
+	original, err := source.ReadFile("/docs/a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := source.ExportTar(&archive); err != nil {
+		t.Fatal(err)
+	}
+	target := newTestFileSystem(t)
+	if err := target.ImportTar(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := target.ReadFile("/docs/a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(file.Content) != "# Notes" {
+		t.Errorf("content = %q, want %q", file.Content, "# Notes")
+	}
+	if file.Metadata.MimeType != "text/markdown" || !slices.Equal(file.Metadata.Tags, []string{"notes", "draft"}) ||
+		file.Metadata.Permissions["access"] != "r" {
+		t.Errorf("metadata = %+v, want %+v", file.Metadata, metadata)
+	}
+	if !file.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("created_at = %v, want %v", file.CreatedAt, original.CreatedAt)
+	}
+
+	link, err := target.ReadLink("/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != "/docs/a.md" {
+		t.Errorf("symlink target = %q, want %q", link, "/docs/a.md")
+	}
+
+	var dirs int
+	if err := target.db.QueryRow(`SELECT COUNT(*) FROM virtual_filesystem WHERE path = '/docs/'`).Scan(&dirs); err != nil {
+		t.Fatal(err)
+	}
+	if dirs != 1 {
+		t.Errorf("directory /docs/ was not imported")
+	}
+}
+
+func TestImportTarRollsBackOnFailure(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/kept.txt", []byte("before"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The third entry's path is too long, so the import fails after the first two were written
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, name := range []string{"/kept.txt", "/new.txt", "/" + strings.Repeat("x", MaxPathLength)} {
+		hdr := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len("after"))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte("after")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.ImportTar(&archive); err == nil {
+		t.Fatal("ImportTar of an archive with an invalid entry succeeded")
+	}
+
+	file, err := fs.ReadFile("/kept.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(file.Content) != "before" {
+		t.Errorf("existing file content = %q after a failed import, want %q", file.Content, "before")
+	}
+	if _, err := fs.ReadFile("/new.txt"); err == nil {
+		t.Error("file from a failed import exists")
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// atomicWriteTempSuffix marks the temporary rows written by AtomicWrite. A row left behind by a crash before the swap
+// holds a complete copy of the content that never replaced its target, and can be deleted.
+const atomicWriteTempSuffix = ".atomic-tmp-"
+
+// AtomicWrite replaces the file at path with content so that readers only ever see the old or the new complete file.
+// The content is first written to a temporary path; then, in a single transaction, any existing file at path is
+// deleted and the temporary file is renamed into place, keeping the original creation time. metadata is used for the
+// new file as given.
+func (fs *TursoFileSystem) AtomicWrite(path string, content []byte, metadata Metadata) error {
+	return fs.AtomicWriteContext(context.Background(), path, content, metadata)
+}
+
+// AtomicWriteContext is like AtomicWrite but runs its queries under ctx.
+func (fs *TursoFileSystem) AtomicWriteContext(ctx context.Context, path string, content []byte, metadata Metadata) error {
+	stored, metadataJSON, err := fs.prepareNewFile(path, content, metadata)
+	if err != nil {
+		return err
+	}
+
+	tempPath := path + atomicWriteTempSuffix + generateUUID()
+	_, err = fs.db.ExecContext(ctx, `
+		INSERT INTO virtual_filesystem (id, path, content, metadata)
+		VALUES (?, ?, ?, ?)
+	`, generateUUID(), tempPath, stored, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("temporary write failed: %w", err)
+	}
+
+	replaced, err := fs.swapIntoPlace(ctx, tempPath, path)
+	if err != nil {
+		if _, cleanupErr := fs.db.ExecContext(ctx, `DELETE FROM virtual_filesystem WHERE path = ?`, tempPath); cleanupErr != nil {
+			slog.Warn("Failed to delete temporary file after failed atomic write", "path", tempPath, "error", cleanupErr)
+		}
+		return err
+	}
+
+	if replaced {
+		fs.publish(FileUpdated, path)
+	} else {
+		fs.publish(FileCreated, path)
+	}
+	return nil
+}
+
+// swapIntoPlace replaces the file at path with the one at tempPath in a single transaction, and reports whether there
+// was a file at path to replace
+func (fs *TursoFileSystem) swapIntoPlace(ctx context.Context, tempPath, path string) (bool, error) {
+	tx, err := fs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE virtual_filesystem
+		SET created_at = COALESCE((SELECT created_at FROM virtual_filesystem WHERE path = ?), created_at)
+		WHERE path = ?
+	`, path, tempPath)
+	if err != nil {
+		return false, fmt.Errorf("error carrying over creation time: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM virtual_filesystem WHERE path = ?`, path)
+	if err != nil {
+		return false, fmt.Errorf("error removing previous file: %w", err)
+	}
+	replaced, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error checking previous file: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE virtual_filesystem
+		SET path = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE path = ?
+	`, path, tempPath)
+	if err != nil {
+		return false, fmt.Errorf("error renaming temporary file: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing atomic write: %w", err)
+	}
+	return replaced > 0, nil
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WithReadCache keeps the content of up to maxEntries recently read files in memory, evicting the least recently used.
+// Each read still makes a cheap query for the file's updated_at and size, and a cached file is only used if both are
+// unchanged, so writes by other processes are seen; writes made through this TursoFileSystem drop the cached file at
+// once. Since updated_at has a resolution of a second, a write by another process that leaves the size unchanged can
+// go unnoticed until the next write to the file if it lands in the same second as the read that cached it.
+func WithReadCache(maxEntries int) FileSystemOption {
+	return func(fs *TursoFileSystem) {
+		if maxEntries > 0 {
+			fs.readCache = newReadCache(maxEntries)
+		}
+	}
+}
+
+// fileVersion identifies the stored version of a file well enough to tell whether a cached copy is current
+type fileVersion struct {
+	updatedAt time.Time
+	size      int64
+}
+
+type cachedFile struct {
+	path    string
+	version fileVersion
+	file    *VirtualFile
+}
+
+// readCache is an LRU cache of files as returned by readFile. A nil *readCache caches nothing.
+type readCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // of *cachedFile, most recently used first
+	entries    map[string]*list.Element
+	// epoch counts invalidations, so a read that raced with a write does not cache its result
+	epoch uint64
+}
+
+func newReadCache(maxEntries int) *readCache {
+	return &readCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the cached file at path if it is at version
+func (c *readCache) get(path string, version fileVersion) (*VirtualFile, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cachedFile)
+	if !entry.version.updatedAt.Equal(version.updatedAt) || entry.version.size != version.size {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return cloneFile(entry.file), true
+}
+
+// currentEpoch returns the number of invalidations so far, to be passed to put
+func (c *readCache) currentEpoch() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.epoch
+}
+
+// put caches a copy of file as the content of path at version, unless anything has been invalidated since epoch was
+// taken: the file may then have been read before a write whose version cannot be told apart from it. Counting
+// invalidations of all paths rather than of each one keeps the cache's memory bounded, at the cost of not caching
+// reads that overlap a write to another file.
+func (c *readCache) put(path string, epoch uint64, version fileVersion, file *VirtualFile) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.epoch != epoch {
+		return
+	}
+
+	entry := &cachedFile{path: path, version: version, file: cloneFile(file)}
+	if elem, ok := c.entries[path]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[path] = c.order.PushFront(entry)
+	if c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedFile).path)
+	}
+}
+
+// invalidate drops the cached files at paths
+func (c *readCache) invalidate(paths ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	for _, path := range paths {
+		if elem, ok := c.entries[path]; ok {
+			c.order.Remove(elem)
+			delete(c.entries, path)
+		}
+	}
+}
+
+// readFileCached is readFile through the read cache
+func (fs *TursoFileSystem) readFileCached(ctx context.Context, path string) (*VirtualFile, error) {
+	// The version is queried before the content, so a write in between can only make the cached copy look stale. A write
+	// through this file system after the content is read is caught by the epoch instead.
+	epoch := fs.readCache.currentEpoch()
+	var version fileVersion
+	err := fs.db.QueryRowContext(ctx, `
+		SELECT updated_at, `+storedSizeSQL+` FROM virtual_filesystem WHERE path = ?
+	`, path).Scan(&version.updatedAt, &version.size)
+	if err == sql.ErrNoRows {
+		fs.readCache.invalidate(path)
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if file, ok := fs.readCache.get(path, version); ok {
+		return file, nil
+	}
+
+	file, err := fs.queryFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	fs.readCache.put(path, epoch, version, file)
+	return file, nil
+}
+
+// cloneFile copies file deeply enough that changes to the copy do not affect the original
+func cloneFile(file *VirtualFile) *VirtualFile {
+	clone := *file
+	clone.Content = slices.Clone(file.Content)
+	clone.Metadata.Tags = slices.Clone(file.Metadata.Tags)
+	clone.Metadata.Permissions = maps.Clone(file.Metadata.Permissions)
+	return &clone
+}
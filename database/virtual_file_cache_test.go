@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadCache(t *testing.T) {
+	fs := newTestFileSystem(t)
+	WithReadCache(2)(fs)
+
+	for _, path := range []string{"/a.txt", "/b.txt", "/c.txt"} {
+		if err := fs.CreateFile(path, []byte("old"), Metadata{Tags: []string{"t"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Changing the content behind the cache's back, keeping updated_at and the size, shows whether a read was served
+	// from the cache
+	overwrite := func(path, content string) {
+		t.Helper()
+		if _, err := fs.db.Exec(`UPDATE virtual_filesystem SET content = ? WHERE path = ?`, []byte(content), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) string {
+		t.Helper()
+		file, err := fs.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(file.Content)
+	}
+
+	file, err := fs.ReadFile("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Content[0] = 'X'
+	file.Metadata.Tags[0] = "changed"
+	overwrite("/a.txt", "new")
+	cached, err := fs.ReadFile("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cached.Content) != "old" || cached.Metadata.Tags[0] != "t" {
+		t.Errorf("cached read = %q with tags %v, want %q with tags [t]", cached.Content, cached.Metadata.Tags, "old")
+	}
+
+	// A write through the file system invalidates the entry
+	if err := fs.UpdateFile("/a.txt", []byte("newer")); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("/a.txt"); got != "newer" {
+		t.Errorf("read after UpdateFile = %q, want %q", got, "newer")
+	}
+
+	// A write by another process is caught by the size check
+	if _, err := fs.db.Exec(`UPDATE virtual_filesystem SET content = ? WHERE path = ?`, []byte("newest"), "/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("/a.txt"); got != "newest" {
+		t.Errorf("read after an external write = %q, want %q", got, "newest")
+	}
+
+	// Reading b and c evicts a, the least recently used
+	read("/b.txt")
+	read("/c.txt")
+	overwrite("/a.txt", "evicted")
+	if got := read("/a.txt"); got != "evicted" {
+		t.Errorf("read after eviction = %q, want %q", got, "evicted")
+	}
+
+	if err := fs.DeleteFile("/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile("/c.txt"); err == nil {
+		t.Error("read of a deleted file succeeded")
+	}
+}
+
+func TestReadCacheSkipsReadRacingWrite(t *testing.T) {
+	fs := newTestFileSystem(t)
+	WithReadCache(2)(fs)
+	ctx := context.Background()
+
+	if err := fs.CreateFile("/a.txt", []byte("old"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A read takes the epoch and the content, then a write of the same size lands in the same second before the
+	// read caches what it got, so the version cannot tell the old content from the new
+	epoch := fs.readCache.currentEpoch()
+	stale, err := fs.queryFile(ctx, "/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.UpdateFile("/a.txt", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	var version fileVersion
+	err = fs.db.QueryRow(`SELECT updated_at, `+storedSizeSQL+` FROM virtual_filesystem WHERE path = ?`, "/a.txt").
+		Scan(&version.updatedAt, &version.size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs.readCache.put("/a.txt", epoch, version, stale)
+
+	file, err := fs.ReadFile("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(file.Content) != "new" {
+		t.Errorf("read after a racing write = %q, want %q", file.Content, "new")
+	}
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ContentEncodingGzip is recorded in Metadata.ContentEncoding for content stored gzip compressed.
+const ContentEncodingGzip = "gzip"
+
+// storedSizeSQL is the SQL expression for a file's uncompressed content size, whatever its stored form
+const storedSizeSQL = `COALESCE(json_extract(metadata, '$.uncompressed_size'), LENGTH(content), 0)`
+
+// WithAtRestCompression gzips file content of at least threshold bytes before storing it, when that makes it smaller.
+// The encoding is recorded in the stored metadata and content is decompressed transparently on read, so callers only
+// ever see the original content; a threshold of 0 disables compression. Files stored before compression was enabled,
+// or with a different threshold, remain readable.
+func WithAtRestCompression(threshold int) FileSystemOption {
+	return func(fs *TursoFileSystem) {
+		fs.compressionThreshold = threshold
+	}
+}
+
+// encodeContent returns content in the form to store it in and the encoding used, if any
+func (fs *TursoFileSystem) encodeContent(content []byte) ([]byte, string, error) {
+	if fs.compressionThreshold <= 0 || len(content) < fs.compressionThreshold {
+		return content, "", nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, "", fmt.Errorf("compression failed: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("compression failed: %w", err)
+	}
+
+	if buf.Len() >= len(content) {
+		return content, "", nil
+	}
+	return buf.Bytes(), ContentEncodingGzip, nil
+}
+
+// encodingPatch returns a JSON merge patch (for SQLite's json_patch) recording the stored encoding of content in a
+// file's metadata, or removing a previous encoding if the content is stored as is
+func encodingPatch(encoding string, size int) string {
+	if encoding == "" {
+		return `{"content_encoding":null,"uncompressed_size":null}`
+	}
+	patch, _ := json.Marshal(map[string]interface{}{"content_encoding": encoding, "uncompressed_size": size})
+	return string(patch)
+}
+
+// decodeContent replaces a file's stored content with its original content, and clears the storage details from
+// its metadata
+func decodeContent(file *VirtualFile) error {
+	if file.Metadata.ContentEncoding == ContentEncodingGzip {
+		zr, err := gzip.NewReader(bytes.NewReader(file.Content))
+		if err != nil {
+			return fmt.Errorf("decompression failed for %s: %w", file.Path, err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			return fmt.Errorf("decompression failed for %s: %w", file.Path, err)
+		}
+		file.Content = content
+	} else if file.Metadata.ContentEncoding != "" {
+		return fmt.Errorf("unsupported content encoding %q for %s", file.Metadata.ContentEncoding, file.Path)
+	}
+
+	clearStorageMetadata(&file.Metadata)
+	return nil
+}
+
+// clearStorageMetadata removes the details of how content is stored, which callers never see
+func clearStorageMetadata(metadata *Metadata) {
+	metadata.ContentEncoding = ""
+	metadata.UncompressedSize = 0
+}
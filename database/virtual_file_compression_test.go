@@ -0,0 +1,45 @@
+package database
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAtRestCompressionRoundTrip(t *testing.T) {
+	fs := &TursoFileSystem{}
+	WithAtRestCompression(1024)(fs)
+
+	small := []byte("short note")
+	stored, encoding, err := fs.encodeContent(small)
+	if err != nil {
+		t.Fatalf("encodeContent returned error: %v", err)
+	}
+	if encoding != "" || !bytes.Equal(stored, small) {
+		t.Fatalf("content below the threshold was encoded as %q", encoding)
+	}
+
+	large := []byte(strings.Repeat("# Quarterly report\n\nRevenue grew.\n", 200))
+	stored, encoding, err = fs.encodeContent(large)
+	if err != nil {
+		t.Fatalf("encodeContent returned error: %v", err)
+	}
+	if encoding != ContentEncodingGzip || len(stored) >= len(large) {
+		t.Fatalf("content above the threshold was not compressed: encoding %q, %d bytes", encoding, len(stored))
+	}
+
+	file := VirtualFile{
+		Path:     "/reports/q1.md",
+		Content:  stored,
+		Metadata: Metadata{MimeType: "text/markdown", ContentEncoding: encoding, UncompressedSize: int64(len(large))},
+	}
+	if err := decodeContent(&file); err != nil {
+		t.Fatalf("decodeContent returned error: %v", err)
+	}
+	if !bytes.Equal(file.Content, large) {
+		t.Fatalf("decoded content does not match the original")
+	}
+	if file.Metadata.ContentEncoding != "" || file.Metadata.UncompressedSize != 0 {
+		t.Fatalf("storage details were not cleared from metadata: %+v", file.Metadata)
+	}
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrDirectoryNotEmpty is returned by DeleteDirectory when a non-recursive delete finds entries below the directory.
+	ErrDirectoryNotEmpty = errors.New("directory not empty")
+	// ErrIsDirectory is returned by writes to the content of a directory entry.
+	ErrIsDirectory = errors.New("is a directory")
+)
+
+// DeleteDirectory removes the directory at path and returns the number of entries deleted, including the directory
+// entry itself. Without recursive it fails with ErrDirectoryNotEmpty if anything is stored below path; with recursive
+// everything below path is deleted too, like rm -rf, in a single transaction so the subtree is removed entirely or not
+// at all. Files stored below path without a directory entry of their own are removed as well.
+func (fs *TursoFileSystem) DeleteDirectory(path string, recursive bool) (int64, error) {
+	return fs.DeleteDirectoryContext(context.Background(), path, recursive)
+}
+
+// DeleteDirectoryContext is like DeleteDirectory but runs its queries under ctx.
+func (fs *TursoFileSystem) DeleteDirectoryContext(ctx context.Context, path string, recursive bool) (int64, error) {
+	// Ensure path ends with / for directory matching
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	tx, err := fs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if !recursive {
+		var children int
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM virtual_filesystem
+			WHERE path LIKE ? || '%' ESCAPE '\' AND path <> ?
+		`, escapeLike(path), path).Scan(&children)
+		if err != nil {
+			return 0, fmt.Errorf("database error: %w", err)
+		}
+		if children > 0 {
+			return 0, fmt.Errorf("%w: %s", ErrDirectoryNotEmpty, path)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM virtual_filesystem
+		WHERE path LIKE ? || '%' ESCAPE '\'
+		RETURNING path
+	`, escapeLike(path))
+	if err != nil {
+		return 0, fmt.Errorf("delete failed: %w", err)
+	}
+	defer rows.Close()
+
+	var deleted []string
+	for rows.Next() {
+		var deletedPath string
+		if err := rows.Scan(&deletedPath); err != nil {
+			return 0, fmt.Errorf("row scan failed: %w", err)
+		}
+		deleted = append(deleted, deletedPath)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("delete failed: %w", err)
+	}
+	if len(deleted) == 0 {
+		return 0, errors.New("directory not found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing delete: %w", err)
+	}
+
+	fs.publish(FileDeleted, deleted...)
+	return int64(len(deleted)), nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"change with context",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			"--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{"from empty", "", "a\n", "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+a\n"},
+		{
+			"missing final newline",
+			"a\nb",
+			"a\nb\n",
+			"--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a/f", "b/f", tt.old, tt.new); got != tt.want {
+				t.Errorf("diff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffFile(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/notes.txt", []byte("keep\nold\n"), Metadata{MimeType: "text/plain"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fs.DiffFile("/notes.txt", []byte("keep\nnew\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--- a/notes.txt\n+++ b/notes.txt\n@@ -1,2 +1,2 @@\n keep\n-old\n+new\n"
+	if got != want {
+		t.Errorf("diff =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := fs.CreateFile("/image.png", []byte("\x89PNG"), Metadata{MimeType: "image/png"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.DiffFile("/image.png", []byte("\x89PNG!")); !errors.Is(err, ErrBinaryContent) {
+		t.Errorf("err = %v, want ErrBinaryContent", err)
+	}
+}
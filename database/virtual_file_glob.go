@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GlobFiles returns the files whose path matches pattern, ordered by path. In the pattern, * matches any run of
+// characters within a path segment, ? a single character within a segment, and ** any number of whole segments, so
+// "/logs/*.json" matches JSON files directly in /logs and "/src/**/*.go" Go files at any depth below /src.
+func (fs *TursoFileSystem) GlobFiles(pattern string) ([]VirtualFile, error) {
+	return fs.GlobFilesContext(context.Background(), pattern)
+}
+
+// GlobFilesContext is like GlobFiles but runs its queries under ctx.
+func (fs *TursoFileSystem) GlobFilesContext(ctx context.Context, pattern string) ([]VirtualFile, error) {
+	re, prefix, err := compileGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := fs.db.QueryContext(ctx, `
+		SELECT id, path, content, metadata, created_at, updated_at
+		FROM virtual_filesystem
+		WHERE path LIKE ? || '%' ESCAPE '\'
+		ORDER BY path
+	`, escapeLike(prefix))
+
+	if err != nil {
+		return nil, fmt.Errorf("glob query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var files []VirtualFile
+	for rows.Next() {
+		var file VirtualFile
+		var metadataStr string
+
+		err := rows.Scan(
+			&file.ID,
+			&file.Path,
+			&file.Content,
+			&metadataStr,
+			&file.CreatedAt,
+			&file.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("row scan failed: %w", err)
+		}
+		if !re.MatchString(file.Path) {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
+			return nil, fmt.Errorf("metadata parse error: %w", err)
+		}
+		if err := decodeContent(&file); err != nil {
+			return nil, err
+		}
+		markSymlink(&file)
+
+		files = append(files, file)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating files: %w", err)
+	}
+
+	return files, nil
+}
+
+// GlobFileInfos is like GlobFiles but returns file information without loading any content
+func (fs *TursoFileSystem) GlobFileInfos(pattern string) ([]FileInfo, error) {
+	return fs.GlobFileInfosContext(context.Background(), pattern)
+}
+
+// GlobFileInfosContext is like GlobFileInfos but runs its queries under ctx.
+func (fs *TursoFileSystem) GlobFileInfosContext(ctx context.Context, pattern string) ([]FileInfo, error) {
+	re, prefix, err := compileGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := fs.db.QueryContext(ctx, `
+		SELECT id, path, `+storedSizeSQL+`, metadata, created_at, updated_at
+		FROM virtual_filesystem
+		WHERE path LIKE ? || '%' ESCAPE '\'
+		ORDER BY path
+	`, escapeLike(prefix))
+
+	if err != nil {
+		return nil, fmt.Errorf("glob query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var infos []FileInfo
+	for rows.Next() {
+		var info FileInfo
+		var metadataStr string
+
+		err := rows.Scan(
+			&info.ID,
+			&info.Path,
+			&info.Size,
+			&metadataStr,
+			&info.CreatedAt,
+			&info.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("row scan failed: %w", err)
+		}
+		if !re.MatchString(info.Path) {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(metadataStr), &info.Metadata); err != nil {
+			return nil, fmt.Errorf("metadata parse error: %w", err)
+		}
+		clearStorageMetadata(&info.Metadata)
+
+		infos = append(infos, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating files: %w", err)
+	}
+
+	return infos, nil
+}
+
+// compileGlob translates a glob pattern into an anchored regular expression, and returns the literal prefix before
+// the first wildcard so the database can narrow candidates using the path index
+func compileGlob(pattern string) (*regexp.Regexp, string, error) {
+	if pattern == "" {
+		return nil, "", fmt.Errorf("empty glob pattern")
+	}
+
+	prefix := pattern
+	if i := strings.IndexAny(pattern, "*?"); i >= 0 {
+		prefix = pattern[:i]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more whole segments
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	return re, prefix, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,36 @@
+package database
+
+import "testing"
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		path       string
+		match      bool
+		wantPrefix string
+	}{
+		{"/logs/*.json", "/logs/a.json", true, "/logs/"},
+		{"/logs/*.json", "/logs/sub/a.json", false, "/logs/"},
+		{"/logs/?.json", "/logs/a.json", true, "/logs/"},
+		{"/logs/?.json", "/logs/ab.json", false, "/logs/"},
+		{"/src/**/*.go", "/src/main.go", true, "/src/"},
+		{"/src/**/*.go", "/src/a/b/c.go", true, "/src/"},
+		{"/src/**/*.go", "/src/a/b/c.txt", false, "/src/"},
+		{"/src/**", "/src/a/b", true, "/src/"},
+		{"/notes.md", "/notes.md", true, "/notes.md"},
+		{"/a.b/*", "/axb/c", false, "/a.b/"},
+	}
+
+	for _, tt := range tests {
+		re, prefix, err := compileGlob(tt.pattern)
+		if err != nil {
+			t.Fatalf("compileGlob(%q) returned error: %v", tt.pattern, err)
+		}
+		if prefix != tt.wantPrefix {
+			t.Fatalf("compileGlob(%q) prefix = %q, want %q", tt.pattern, prefix, tt.wantPrefix)
+		}
+		if got := re.MatchString(tt.path); got != tt.match {
+			t.Fatalf("compileGlob(%q) matching %q = %v, want %v", tt.pattern, tt.path, got, tt.match)
+		}
+	}
+}
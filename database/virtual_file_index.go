@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// metadataIndexSchemas index the metadata fields that are queried directly. mime_type is a generated column over the
+// metadata JSON and tags are mirrored into file_tags by triggers, so both stay current on every write path without
+// the writers having to maintain them.
+var metadataIndexSchemas = []string{
+	`CREATE INDEX IF NOT EXISTS idx_vfs_mime_type ON virtual_filesystem(mime_type)`,
+
+	`CREATE TABLE IF NOT EXISTS file_tags (
+		file_id TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (file_id, tag)
+	)`,
+
+	`CREATE INDEX IF NOT EXISTS idx_file_tags_tag ON file_tags(tag)`,
+
+	`CREATE TRIGGER IF NOT EXISTS vfs_tags_insert AFTER INSERT ON virtual_filesystem
+	BEGIN
+		INSERT OR IGNORE INTO file_tags (file_id, tag)
+		SELECT NEW.id, value FROM json_each(NEW.metadata, '$.tags') WHERE type = 'text';
+	END`,
+
+	`CREATE TRIGGER IF NOT EXISTS vfs_tags_update AFTER UPDATE OF id, metadata ON virtual_filesystem
+	BEGIN
+		DELETE FROM file_tags WHERE file_id = OLD.id;
+		INSERT OR IGNORE INTO file_tags (file_id, tag)
+		SELECT NEW.id, value FROM json_each(NEW.metadata, '$.tags') WHERE type = 'text';
+	END`,
+
+	`CREATE TRIGGER IF NOT EXISTS vfs_tags_delete AFTER DELETE ON virtual_filesystem
+	BEGIN
+		DELETE FROM file_tags WHERE file_id = OLD.id;
+	END`,
+}
+
+// migrateMetadataIndexes adds the mime_type column and file_tags table to databases created before they existed,
+// backfilling the tags of existing files once, when file_tags is first created. The generated column needs no
+// backfill.
+func (fs *TursoFileSystem) migrateMetadataIndexes() error {
+	var hasMimeTypeColumn int
+	err := fs.db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_xinfo('virtual_filesystem') WHERE name = 'mime_type'
+	`).Scan(&hasMimeTypeColumn)
+	if err != nil {
+		return fmt.Errorf("error inspecting virtual_filesystem table: %w", err)
+	}
+	if hasMimeTypeColumn == 0 {
+		_, err := fs.db.Exec(`
+			ALTER TABLE virtual_filesystem
+			ADD COLUMN mime_type TEXT GENERATED ALWAYS AS (json_extract(metadata, '$.mime_type')) VIRTUAL
+		`)
+		if err != nil {
+			return fmt.Errorf("error adding mime_type column: %w", err)
+		}
+	}
+
+	var hasTagsTable int
+	err = fs.db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'file_tags'
+	`).Scan(&hasTagsTable)
+	if err != nil {
+		return fmt.Errorf("error inspecting schema: %w", err)
+	}
+
+	for _, schema := range metadataIndexSchemas {
+		if _, err := fs.db.Exec(schema); err != nil {
+			return fmt.Errorf("error creating metadata indexes: %w", err)
+		}
+	}
+
+	if hasTagsTable == 0 {
+		_, err := fs.db.Exec(`
+			INSERT OR IGNORE INTO file_tags (file_id, tag)
+			SELECT v.id, t.value FROM virtual_filesystem v, json_each(v.metadata, '$.tags') t WHERE t.type = 'text'
+		`)
+		if err != nil {
+			return fmt.Errorf("error backfilling file tags: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FilesByMimeType returns the files whose metadata has the given MIME type, using the mime_type index
+func (fs *TursoFileSystem) FilesByMimeType(mimeType string) ([]VirtualFile, error) {
+	return fs.FilesByMimeTypeContext(context.Background(), mimeType)
+}
+
+// FilesByMimeTypeContext is like FilesByMimeType but runs its queries under ctx.
+func (fs *TursoFileSystem) FilesByMimeTypeContext(ctx context.Context, mimeType string) ([]VirtualFile, error) {
+	rows, err := fs.db.QueryContext(ctx, `
+		SELECT id, path, content, metadata, created_at, updated_at
+		FROM virtual_filesystem
+		WHERE mime_type = ?
+		ORDER BY path
+	`, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return scanFiles(rows)
+}
+
+// FilesByTag returns the files tagged with tag, using the file_tags index
+func (fs *TursoFileSystem) FilesByTag(tag string) ([]VirtualFile, error) {
+	return fs.FilesByTagContext(context.Background(), tag)
+}
+
+// FilesByTagContext is like FilesByTag but runs its queries under ctx.
+func (fs *TursoFileSystem) FilesByTagContext(ctx context.Context, tag string) ([]VirtualFile, error) {
+	rows, err := fs.db.QueryContext(ctx, `
+		SELECT v.id, v.path, v.content, v.metadata, v.created_at, v.updated_at
+		FROM file_tags t
+		JOIN virtual_filesystem v ON v.id = t.file_id
+		WHERE t.tag = ?
+		ORDER BY v.path
+	`, tag)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return scanFiles(rows)
+}
+
+// scanFiles reads rows of (id, path, content, metadata, created_at, updated_at) into decoded files, closing rows
+func scanFiles(rows *sql.Rows) ([]VirtualFile, error) {
+	defer rows.Close()
+
+	var files []VirtualFile
+	for rows.Next() {
+		var file VirtualFile
+		var metadataStr string
+
+		err := rows.Scan(
+			&file.ID,
+			&file.Path,
+			&file.Content,
+			&metadataStr,
+			&file.CreatedAt,
+			&file.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("row scan failed: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
+			return nil, fmt.Errorf("metadata parse error: %w", err)
+		}
+		if err := decodeContent(&file); err != nil {
+			return nil, err
+		}
+		markSymlink(&file)
+
+		files = append(files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+)
+
+func TestMigrateMetadataIndexes(t *testing.T) {
+	db, err := sql.Open("libsql", testDSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	// A virtual_filesystem table from before the mime_type column and file_tags table existed, with files in it
+	_, err = db.Exec(`
+		CREATE TABLE virtual_filesystem (
+			id TEXT PRIMARY KEY,
+			path TEXT NOT NULL UNIQUE,
+			content BLOB,
+			metadata JSON,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(path)
+		);
+		INSERT INTO virtual_filesystem (id, path, content, metadata) VALUES
+			('1', '/notes.md', 'a', '{"mime_type": "text/markdown", "tags": ["notes", "draft"]}'),
+			('2', '/todo.md', 'b', '{"mime_type": "text/markdown", "tags": ["notes"]}'),
+			('3', '/data.json', 'c', '{"mime_type": "application/json", "tags": []}');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewTursoFileSystemFromDB(db)
+	if err := fs.initialize(); err != nil {
+		t.Fatal(err)
+	}
+	// Applying the migration again changes nothing
+	if err := fs.initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := func(files []VirtualFile, err error) []string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		return paths
+	}
+
+	if got := paths(fs.FilesByMimeType("text/markdown")); !slices.Equal(got, []string{"/notes.md", "/todo.md"}) {
+		t.Errorf("FilesByMimeType(text/markdown) = %v, want the existing markdown files", got)
+	}
+	if got := paths(fs.FilesByTag("notes")); !slices.Equal(got, []string{"/notes.md", "/todo.md"}) {
+		t.Errorf("FilesByTag(notes) = %v, want the backfilled tags", got)
+	}
+	if got := paths(fs.FilesByTag("draft")); !slices.Equal(got, []string{"/notes.md"}) {
+		t.Errorf("FilesByTag(draft) = %v, want [/notes.md]", got)
+	}
+
+	// Files written after the migration are indexed by the generated column and the triggers
+	metadata := Metadata{MimeType: "application/json", Tags: []string{"notes"}}
+	if err := fs.CreateFile("/more.json", []byte("{}"), metadata); err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(fs.FilesByMimeType("application/json")); !slices.Equal(got, []string{"/data.json", "/more.json"}) {
+		t.Errorf("FilesByMimeType(application/json) = %v, want both JSON files", got)
+	}
+	if got := paths(fs.FilesByTag("notes")); !slices.Equal(got, []string{"/more.json", "/notes.md", "/todo.md"}) {
+		t.Errorf("FilesByTag(notes) after a write = %v, want the new file too", got)
+	}
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMimeTypeNotAllowed is returned when a write's detected MIME type is rejected by the file system's MIME type
+// policy (see WithAllowedMimeTypes and WithDeniedMimeTypes).
+var ErrMimeTypeNotAllowed = errors.New("mime type not allowed")
+
+// MIME types reported by DetectMimeType for native executables
+const (
+	MimeTypeELF   = "application/x-executable"
+	MimeTypePE    = "application/vnd.microsoft.portable-executable"
+	MimeTypeMachO = "application/x-mach-binary"
+)
+
+// ExecutableMimeTypes lists the MIME types detected for native executables, for use with WithDeniedMimeTypes.
+var ExecutableMimeTypes = []string{MimeTypeELF, MimeTypePE, MimeTypeMachO}
+
+// WithAllowedMimeTypes restricts file writes to content whose detected MIME type is one of mimeTypes. Directories and
+// symbolic links are not affected.
+func WithAllowedMimeTypes(mimeTypes ...string) FileSystemOption {
+	return func(fs *TursoFileSystem) {
+		fs.allowedMimeTypes = mimeTypeSet(mimeTypes)
+	}
+}
+
+// WithDeniedMimeTypes rejects file writes whose content has one of the detected mimeTypes, e.g.
+// WithDeniedMimeTypes(ExecutableMimeTypes...). The denylist is checked before any allowlist.
+func WithDeniedMimeTypes(mimeTypes ...string) FileSystemOption {
+	return func(fs *TursoFileSystem) {
+		fs.deniedMimeTypes = mimeTypeSet(mimeTypes)
+	}
+}
+
+// checkMimeType returns ErrMimeTypeNotAllowed if the detected MIME type of content written at path is rejected by the
+// configured policy
+func (fs *TursoFileSystem) checkMimeType(path string, content []byte) error {
+	if fs.allowedMimeTypes == nil && fs.deniedMimeTypes == nil {
+		return nil
+	}
+
+	mimeType := fs.DetectMimeType(path, content)
+	if fs.deniedMimeTypes[mimeType] || (fs.allowedMimeTypes != nil && !fs.allowedMimeTypes[mimeType]) {
+		return fmt.Errorf("%w: %s is %s", ErrMimeTypeNotAllowed, path, mimeType)
+	}
+	return nil
+}
+
+// DefaultMimeTypes maps lowercase file extensions, with their leading dot, to the MIME types detected for them. Files
+// with other extensions have their MIME type sniffed from their content. Use WithMimeTypeMap to add to or override it
+// for one file system.
+var DefaultMimeTypes = map[string]string{
+	".txt":  "text/plain",
+	".log":  "text/plain",
+	".md":   "text/markdown",
+	".html": "text/html",
+	".htm":  "text/html",
+	".css":  "text/css",
+	".csv":  "text/csv",
+	".tsv":  "text/tab-separated-values",
+	".xml":  "application/xml",
+	".json": "application/json",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".toml": "application/toml",
+	".js":   "text/javascript",
+	".mjs":  "text/javascript",
+	".ts":   "text/x-typescript",
+	".go":   "text/x-go",
+	".py":   "text/x-python",
+	".sh":   "application/x-sh",
+	".sql":  "application/sql",
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+	".zip":  "application/zip",
+	".gz":   "application/gzip",
+	".tar":  "application/x-tar",
+}
+
+// WithMimeTypeMap adds the extension to MIME type mappings in mimeTypes to DefaultMimeTypes, overriding the defaults
+// for extensions in both, e.g. WithMimeTypeMap(map[string]string{".ipynb": "application/x-ipynb+json"}). Extensions
+// are matched case-insensitively, and the leading dot may be left out.
+func WithMimeTypeMap(mimeTypes map[string]string) FileSystemOption {
+	return func(fs *TursoFileSystem) {
+		merged := make(map[string]string, len(DefaultMimeTypes)+len(mimeTypes))
+		for ext, mimeType := range DefaultMimeTypes {
+			merged[ext] = mimeType
+		}
+		for ext, mimeType := range mimeTypes {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			merged[ext] = mimeType
+		}
+		fs.mimeTypes = merged
+	}
+}
+
+// DetectMimeType returns the MIME type of content written at path: that of its extension (see DefaultMimeTypes and
+// WithMimeTypeMap), or else one sniffed from the content. Native executables are always detected as such.
+func (fs *TursoFileSystem) DetectMimeType(path string, content []byte) string {
+	if fs.mimeTypes != nil {
+		return detectMimeType(path, content, fs.mimeTypes)
+	}
+	return detectMimeType(path, content, DefaultMimeTypes)
+}
+
+func mimeTypeSet(mimeTypes []string) map[string]bool {
+	set := make(map[string]bool, len(mimeTypes))
+	for _, mimeType := range mimeTypes {
+		set[strings.ToLower(strings.TrimSpace(mimeType))] = true
+	}
+	return set
+}
+
+// sniffExecutable recognises native executables by their magic bytes
+func sniffExecutable(content []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(content, []byte("\x7fELF")):
+		return MimeTypeELF, true
+	case isPortableExecutable(content):
+		return MimeTypePE, true
+	case bytes.HasPrefix(content, []byte{0xfe, 0xed, 0xfa, 0xce}),
+		bytes.HasPrefix(content, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.HasPrefix(content, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(content, []byte{0xcf, 0xfa, 0xed, 0xfe}):
+		return MimeTypeMachO, true
+	}
+	return "", false
+}
+
+// isPortableExecutable reports whether content is a Windows PE image: an "MZ" DOS header whose e_lfanew field points at
+// a "PE\0\0" signature. Checking both avoids flagging text that merely starts with "MZ".
+func isPortableExecutable(content []byte) bool {
+	if len(content) < 0x40 || !bytes.HasPrefix(content, []byte("MZ")) {
+		return false
+	}
+	offset := int64(binary.LittleEndian.Uint32(content[0x3c:]))
+	return offset+4 <= int64(len(content)) && bytes.Equal(content[offset:offset+4], []byte("PE\x00\x00"))
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestCheckMimeType(t *testing.T) {
+	elf := []byte("\x7fELF\x02\x01\x01\x00")
+	pe := make([]byte, 0x80)
+	copy(pe, "MZ")
+	binary.LittleEndian.PutUint32(pe[0x3c:], 0x40)
+	copy(pe[0x40:], "PE\x00\x00")
+
+	fs := &TursoFileSystem{}
+	WithDeniedMimeTypes(ExecutableMimeTypes...)(fs)
+
+	tests := []struct {
+		name    string
+		path    string
+		content []byte
+		allowed bool
+	}{
+		{"plain text", "/notes.txt", []byte("hello"), true},
+		{"text starting with MZ", "/notes.txt", []byte("MZ is a text file, not an executable"), true},
+		{"elf binary", "/bin/tool", elf, false},
+		{"elf disguised as text", "/notes.txt", elf, false},
+		{"pe binary", "/setup.exe", pe, false},
+	}
+
+	for _, tt := range tests {
+		err := fs.checkMimeType(tt.path, tt.content)
+		if tt.allowed && err != nil {
+			t.Fatalf("%s: checkMimeType returned %v, want nil", tt.name, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrMimeTypeNotAllowed) {
+			t.Fatalf("%s: checkMimeType returned %v, want ErrMimeTypeNotAllowed", tt.name, err)
+		}
+	}
+}
+
+func TestCheckMimeTypeAllowlist(t *testing.T) {
+	fs := &TursoFileSystem{}
+	WithAllowedMimeTypes("text/plain", "text/markdown")(fs)
+
+	if err := fs.checkMimeType("/readme.md", []byte("# Title")); err != nil {
+		t.Fatalf("checkMimeType for markdown returned %v, want nil", err)
+	}
+	if err := fs.checkMimeType("/data.json", []byte(`{}`)); !errors.Is(err, ErrMimeTypeNotAllowed) {
+		t.Fatalf("checkMimeType for JSON returned %v, want ErrMimeTypeNotAllowed", err)
+	}
+}
+
+func TestDetectMimeType(t *testing.T) {
+	fs := &TursoFileSystem{}
+	custom := &TursoFileSystem{}
+	WithMimeTypeMap(map[string]string{"IPYNB": "application/x-ipynb+json", ".csv": "application/csv"})(custom)
+
+	tests := []struct {
+		fs      *TursoFileSystem
+		path    string
+		content []byte
+		want    string
+	}{
+		{fs, "/data/report.CSV", []byte("a,b\n1,2\n"), "text/csv"},
+		{fs, "/config.yaml", []byte("key: value\n"), "application/yaml"},
+		{fs, "/doc.pdf", nil, "application/pdf"},
+		{fs, "/notes", []byte("plain words"), "text/plain"},
+		{fs, "/empty", nil, "application/octet-stream"},
+		{fs, "/tool.txt", []byte("\x7fELF\x02\x01\x01\x00"), MimeTypeELF},
+		{custom, "/analysis.ipynb", []byte(`{"cells": []}`), "application/x-ipynb+json"},
+		{custom, "/data.csv", []byte("a,b\n"), "application/csv"},
+		{custom, "/readme.md", []byte("# Title"), "text/markdown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.fs.DetectMimeType(tt.path, tt.content); got != tt.want {
+			t.Errorf("DetectMimeType(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if DefaultMimeTypes[".csv"] != "text/csv" {
+		t.Errorf("WithMimeTypeMap modified DefaultMimeTypes")
+	}
+}
+
+func TestImportTarMimePolicy(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, entry := range []struct {
+		name    string
+		content string
+	}{
+		{"/notes.txt", "hello"},
+		{"/notes-too.txt", "\x7fELF\x02\x01\x01\x00"},
+	} {
+		hdr := &tar.Header{Name: entry.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(entry.content))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := newTestFileSystem(t)
+	WithDeniedMimeTypes(ExecutableMimeTypes...)(fs)
+
+	if err := fs.ImportTar(&archive); !errors.Is(err, ErrMimeTypeNotAllowed) {
+		t.Fatalf("ImportTar of an archive with an executable returned %v, want ErrMimeTypeNotAllowed", err)
+	}
+	if _, err := fs.ReadFile("/notes.txt"); err == nil {
+		t.Error("entry before the rejected one was imported")
+	}
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SearchOrder is the column search results are sorted by
+type SearchOrder string
+
+const (
+	SearchOrderPath      SearchOrder = "path"
+	SearchOrderCreatedAt SearchOrder = "created_at"
+	SearchOrderUpdatedAt SearchOrder = "updated_at"
+)
+
+// SearchOptions bounds and orders the results of SearchFilesOpts
+type SearchOptions struct {
+	// Limit caps the number of results; 0 or less returns every match
+	Limit int
+	// Offset skips that many matches, for paging through results together with Limit
+	Offset int
+	// OrderBy sorts the results, by path when empty
+	OrderBy SearchOrder
+	// Descending reverses the sort order, e.g. newest first with SearchOrderUpdatedAt
+	Descending bool
+	// IncludeContent loads each file's content; when false the content column is not read at all and Content is nil
+	IncludeContent bool
+}
+
+// SearchFilesOpts searches file paths and metadata for query like SearchFiles, returning the matches sorted, paged and
+// with or without their content as opts specifies.
+func (fs *TursoFileSystem) SearchFilesOpts(query string, opts SearchOptions) ([]VirtualFile, error) {
+	return fs.SearchFilesOptsContext(context.Background(), query, opts)
+}
+
+// SearchFilesOptsContext is like SearchFilesOpts but runs its queries under ctx.
+func (fs *TursoFileSystem) SearchFilesOptsContext(ctx context.Context, query string, opts SearchOptions) ([]VirtualFile, error) {
+	sqlQuery, args, err := buildSearchQuery(query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := fs.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	defer rows.Close()
+
+	var files []VirtualFile
+	for rows.Next() {
+		var file VirtualFile
+		var metadataStr string
+
+		dest := []any{&file.ID, &file.Path}
+		if opts.IncludeContent {
+			dest = append(dest, &file.Content)
+		}
+		dest = append(dest, &metadataStr, &file.CreatedAt, &file.UpdatedAt)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("row scan failed: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
+			return nil, fmt.Errorf("metadata parse error: %w", err)
+		}
+		if opts.IncludeContent {
+			if err := decodeContent(&file); err != nil {
+				return nil, err
+			}
+		} else {
+			clearStorageMetadata(&file.Metadata)
+		}
+		markSymlink(&file)
+
+		files = append(files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	return files, nil
+}
+
+// buildSearchQuery returns the SQL and arguments for a search. OrderBy is checked against the known columns since it
+// cannot be bound as a parameter.
+func buildSearchQuery(query string, opts SearchOptions) (string, []any, error) {
+	orderBy := opts.OrderBy
+	if orderBy == "" {
+		orderBy = SearchOrderPath
+	}
+	switch orderBy {
+	case SearchOrderPath, SearchOrderCreatedAt, SearchOrderUpdatedAt:
+	default:
+		return "", nil, fmt.Errorf("invalid search order %q", orderBy)
+	}
+	direction := "ASC"
+	if opts.Descending {
+		direction = "DESC"
+	}
+
+	columns := "id, path, metadata, created_at, updated_at"
+	if opts.IncludeContent {
+		columns = "id, path, content, metadata, created_at, updated_at"
+	}
+
+	// SQLite only accepts OFFSET after a LIMIT; -1 means no limit
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM virtual_filesystem
+		WHERE path LIKE ? OR json_remove(metadata, '$.content_encoding', '$.uncompressed_size') LIKE ?
+		ORDER BY %s %s, path ASC
+		LIMIT ? OFFSET ?
+	`, columns, orderBy, direction)
+
+	pattern := "%" + query + "%"
+	return sqlQuery, []any{pattern, pattern, limit, max(opts.Offset, 0)}, nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        SearchOptions
+		wantColumns string
+		wantOrder   string
+		wantLimit   int
+		wantOffset  int
+	}{
+		{"defaults", SearchOptions{}, "id, path, metadata,", "ORDER BY path ASC", -1, 0},
+		{"with content", SearchOptions{IncludeContent: true}, "id, path, content, metadata,", "ORDER BY path ASC", -1, 0},
+		{"paged newest first", SearchOptions{Limit: 20, Offset: 40, OrderBy: SearchOrderUpdatedAt, Descending: true},
+			"id, path, metadata,", "ORDER BY updated_at DESC", 20, 40},
+		{"negative offset", SearchOptions{Offset: -5, OrderBy: SearchOrderCreatedAt}, "id, path, metadata,",
+			"ORDER BY created_at ASC", -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := buildSearchQuery("report", tt.opts)
+			if err != nil {
+				t.Fatalf("buildSearchQuery: %v", err)
+			}
+			if !strings.Contains(query, "SELECT "+tt.wantColumns) {
+				t.Errorf("query does not select %q: %s", tt.wantColumns, query)
+			}
+			if !strings.Contains(query, tt.wantOrder) {
+				t.Errorf("query does not contain %q: %s", tt.wantOrder, query)
+			}
+			if len(args) != 4 || args[0] != "%report%" || args[2] != tt.wantLimit || args[3] != tt.wantOffset {
+				t.Errorf("args = %v, want pattern, limit %d, offset %d", args, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestBuildSearchQueryRejectsUnknownOrder(t *testing.T) {
+	if _, _, err := buildSearchQuery("x", SearchOptions{OrderBy: "content; DROP TABLE virtual_filesystem"}); err == nil {
+		t.Fatal("expected an error for an unknown order column")
+	}
+}
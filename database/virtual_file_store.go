@@ -1,12 +1,14 @@
 package database
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
@@ -19,12 +21,37 @@ type VirtualFile struct {
 	Metadata  Metadata  `json:"metadata"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// LinkTarget is the path a symbolic link points at; it is empty for regular files and directories
+	LinkTarget string `json:"link_target,omitempty"`
 }
 
+// ErrFileExists is returned by CreateFileExclusive when a file already exists at the path.
+var ErrFileExists = errors.New("file already exists")
+
 type Metadata struct {
 	MimeType    string            `json:"mime_type"`
 	Tags        []string          `json:"tags"`
 	Permissions map[string]string `json:"permissions"`
+
+	// ContentEncoding and UncompressedSize record how content is stored at rest (see WithAtRestCompression). They are
+	// managed by the file system and cleared before files are returned to callers.
+	ContentEncoding  string `json:"content_encoding,omitempty"`
+	UncompressedSize int64  `json:"uncompressed_size,omitempty"`
+}
+
+// FileInfo describes a virtual file without its content
+type FileInfo struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Metadata  Metadata  `json:"metadata"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsDir reports whether the entry is a directory
+func (fi *FileInfo) IsDir() bool {
+	return strings.HasSuffix(fi.Path, "/")
 }
 
 // Schema definitions
@@ -53,40 +80,107 @@ var schemas = []string{
 	)`,
 
 	`CREATE INDEX IF NOT EXISTS idx_vfs_path ON virtual_filesystem(path)`,
+
+	`CREATE TABLE IF NOT EXISTS file_locks (
+		path TEXT PRIMARY KEY,
+		lease_id TEXT NOT NULL,
+		owner TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	)`,
 }
 
 // FileSystem interface that the LLM will interact with
 type VirtualFileSystem interface {
 	// Basic file operations
 	CreateFile(path string, content []byte, metadata Metadata) error
+	CreateFileContext(ctx context.Context, path string, content []byte, metadata Metadata) error
+	CreateFileExclusive(path string, content []byte, metadata Metadata) error
+	CreateFileExclusiveContext(ctx context.Context, path string, content []byte, metadata Metadata) error
+	UpsertFile(path string, content []byte, metadata Metadata) error
+	UpsertFileContext(ctx context.Context, path string, content []byte, metadata Metadata) error
 	ReadFile(path string) (*VirtualFile, error)
+	ReadFileContext(ctx context.Context, path string) (*VirtualFile, error)
+	ReadFileString(path string) (string, error)
+	ReadFileStringContext(ctx context.Context, path string) (string, error)
+	DiffFile(path string, newContent []byte) (string, error)
+	DiffFileContext(ctx context.Context, path string, newContent []byte) (string, error)
+	StatFile(path string) (*FileInfo, error)
+	StatFileContext(ctx context.Context, path string) (*FileInfo, error)
+	CreateSymlink(linkPath, targetPath string) error
+	CreateSymlinkContext(ctx context.Context, linkPath, targetPath string) error
+	ReadLink(path string) (string, error)
+	ReadLinkContext(ctx context.Context, path string) (string, error)
 	UpdateFile(path string, content []byte) error
+	UpdateFileContext(ctx context.Context, path string, content []byte) error
+	AppendToFile(path string, data []byte) error
+	AppendToFileContext(ctx context.Context, path string, data []byte) error
+	UpdateFileWithLease(path, leaseID string, content []byte) error
+	UpdateFileWithLeaseContext(ctx context.Context, path, leaseID string, content []byte) error
 	DeleteFile(path string) error
+	DeleteFileContext(ctx context.Context, path string) error
 
 	// Directory operations
 	ListFiles(path string) ([]VirtualFile, error)
+	ListFilesContext(ctx context.Context, path string) ([]VirtualFile, error)
 	CreateDirectory(path string) error
+	CreateDirectoryContext(ctx context.Context, path string) error
+	DeleteDirectory(path string, recursive bool) (int64, error)
+	DeleteDirectoryContext(ctx context.Context, path string, recursive bool) (int64, error)
 
 	// Search and query
 	SearchFiles(query string) ([]VirtualFile, error)
+	SearchFilesContext(ctx context.Context, query string) ([]VirtualFile, error)
+	SearchFilesOpts(query string, opts SearchOptions) ([]VirtualFile, error)
+	SearchFilesOptsContext(ctx context.Context, query string, opts SearchOptions) ([]VirtualFile, error)
+	FilesByMimeType(mimeType string) ([]VirtualFile, error)
+	FilesByMimeTypeContext(ctx context.Context, mimeType string) ([]VirtualFile, error)
+	FilesByTag(tag string) ([]VirtualFile, error)
+	FilesByTagContext(ctx context.Context, tag string) ([]VirtualFile, error)
+
+	// Advisory locking
+	AcquireLock(path, owner string, ttl time.Duration) (string, error)
+	AcquireLockContext(ctx context.Context, path, owner string, ttl time.Duration) (string, error)
+	ReleaseLock(path, leaseID string) error
+	ReleaseLockContext(ctx context.Context, path, leaseID string) error
 
 	// Metadata operations
 	UpdateMetadata(path string, metadata Metadata) error
+	UpdateMetadataContext(ctx context.Context, path string, metadata Metadata) error
 	GetMetadata(path string) (Metadata, error)
+	GetMetadataContext(ctx context.Context, path string) (Metadata, error)
+	DetectMimeType(path string, content []byte) string
+
+	// Change notifications
+	Watch(pathPrefix string) (<-chan FileEvent, func())
 }
 
 // Implementation for Turso
 type TursoFileSystem struct {
 	db *sql.DB
+
+	allowedMimeTypes     map[string]bool
+	deniedMimeTypes      map[string]bool
+	compressionThreshold int
+	rawBinaryStrings     bool
+	mimeTypes            map[string]string
+	readCache            *readCache
+
+	watches watchRegistry
 }
 
-func NewTursoFileSystem(dsn string) (*TursoFileSystem, error) {
+// FileSystemOption represents a functional option type for configuring the TursoFileSystem.
+type FileSystemOption func(*TursoFileSystem)
+
+func NewTursoFileSystem(dsn string, options ...FileSystemOption) (*TursoFileSystem, error) {
 	db, err := sql.Open("libsql", dsn)
 	if err != nil {
 		return nil, err
 	}
 
 	fs := &TursoFileSystem{db: db}
+	for _, opt := range options {
+		opt(fs)
+	}
 	if err := fs.initialize(); err != nil {
 		db.Close()
 		return nil, err
@@ -95,6 +189,16 @@ func NewTursoFileSystem(dsn string) (*TursoFileSystem, error) {
 	return fs, nil
 }
 
+// NewTursoFileSystemFromDB wraps an existing connection, for callers that manage the connection themselves. Unlike
+// NewTursoFileSystem it does not create the schema.
+func NewTursoFileSystemFromDB(db *sql.DB, options ...FileSystemOption) *TursoFileSystem {
+	fs := &TursoFileSystem{db: db}
+	for _, opt := range options {
+		opt(fs)
+	}
+	return fs
+}
+
 func (fs *TursoFileSystem) initialize() error {
 	// Initialize schemas
 	for _, schema := range schemas {
@@ -102,28 +206,173 @@ func (fs *TursoFileSystem) initialize() error {
 			return err
 		}
 	}
-	return nil
+	return fs.migrateMetadataIndexes()
 }
 
+// CreateFile creates a new file. It fails with ErrFileExists if a file already exists at the path; see
+// CreateFileExclusive.
 func (fs *TursoFileSystem) CreateFile(path string, content []byte, metadata Metadata) error {
-	metadataJSON, err := json.Marshal(metadata)
+	return fs.CreateFileContext(context.Background(), path, content, metadata)
+}
+
+// CreateFileContext is like CreateFile but runs its queries under ctx.
+func (fs *TursoFileSystem) CreateFileContext(ctx context.Context, path string, content []byte, metadata Metadata) error {
+	return fs.CreateFileExclusiveContext(ctx, path, content, metadata)
+}
+
+// CreateFileExclusive creates a new file, failing with ErrFileExists if a file already exists at the path. The check
+// is made by the UNIQUE(path) constraint in the same statement as the insert, so concurrent callers cannot both
+// succeed.
+func (fs *TursoFileSystem) CreateFileExclusive(path string, content []byte, metadata Metadata) error {
+	return fs.CreateFileExclusiveContext(context.Background(), path, content, metadata)
+}
+
+// CreateFileExclusiveContext is like CreateFileExclusive but runs its queries under ctx.
+func (fs *TursoFileSystem) CreateFileExclusiveContext(ctx context.Context, path string, content []byte, metadata Metadata) error {
+	stored, metadataJSON, err := fs.prepareNewFile(path, content, metadata)
 	if err != nil {
 		return err
 	}
 
-	if len(content) > MaxFileSize {
-		return fmt.Errorf("file exceeds maximum size of %d bytes", MaxFileSize)
+	_, err = fs.db.ExecContext(ctx, `
+		INSERT INTO virtual_filesystem (id, path, content, metadata)
+		VALUES (?, ?, ?, ?)
+	`, generateUUID(), path, stored, metadataJSON)
+
+	if isUniqueConstraintErr(err) {
+		return fmt.Errorf("%w: %s", ErrFileExists, path)
 	}
-	if len(path) > MaxPathLength {
-		return fmt.Errorf("path exceeds maximum length of %d characters", MaxPathLength)
+	if err != nil {
+		return err
 	}
 
-	_, err = fs.db.Exec(`
+	fs.publish(FileCreated, path)
+	return nil
+}
+
+// UpsertFile creates the file at path, or replaces its content if it already exists, in a single statement. metadata
+// is only used when the file is created; an existing file keeps its metadata, as with UpdateFile. Like UpdateFile, it
+// fails with ErrIsSymlink or ErrIsDirectory if path is a symbolic link or directory.
+func (fs *TursoFileSystem) UpsertFile(path string, content []byte, metadata Metadata) error {
+	return fs.UpsertFileContext(context.Background(), path, content, metadata)
+}
+
+// UpsertFileContext is like UpsertFile but runs its queries under ctx.
+func (fs *TursoFileSystem) UpsertFileContext(ctx context.Context, path string, content []byte, metadata Metadata) error {
+	stored, metadataJSON, err := fs.prepareNewFile(path, content, metadata)
+	if err != nil {
+		return err
+	}
+
+	// On update only the storage details of the new metadata are merged into the existing metadata. The id returned is
+	// the new one only if the row was inserted.
+	id := generateUUID()
+	var storedID string
+	err = fs.db.QueryRowContext(ctx, `
 		INSERT INTO virtual_filesystem (id, path, content, metadata)
 		VALUES (?, ?, ?, ?)
-	`, generateUUID(), path, content, metadataJSON)
+		ON CONFLICT(path) DO UPDATE SET
+			content = excluded.content,
+			metadata = json_patch(virtual_filesystem.metadata, json_object(
+				'content_encoding', json_extract(excluded.metadata, '$.content_encoding'),
+				'uncompressed_size', json_extract(excluded.metadata, '$.uncompressed_size'))),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE `+writableSQL+`
+		RETURNING id
+	`, id, path, stored, metadataJSON).Scan(&storedID)
+
+	if err == sql.ErrNoRows {
+		// The path exists but is not writable
+		if err := fs.unwritableErr(ctx, path); err != nil {
+			return err
+		}
+		return fmt.Errorf("upsert failed: %s changed during the write", path)
+	}
+	if err != nil {
+		return fmt.Errorf("upsert failed: %w", err)
+	}
 
-	return err
+	if storedID == id {
+		fs.publish(FileCreated, path)
+	} else {
+		fs.publish(FileUpdated, path)
+	}
+	return nil
+}
+
+// prepareNewFile checks a file against the size and path limits and the MIME type policy, and returns its content in
+// stored form together with its marshaled metadata
+func (fs *TursoFileSystem) prepareNewFile(path string, content []byte, metadata Metadata) ([]byte, string, error) {
+	if len(content) > MaxFileSize {
+		return nil, "", fmt.Errorf("file exceeds maximum size of %d bytes", MaxFileSize)
+	}
+	if len(path) > MaxPathLength {
+		return nil, "", fmt.Errorf("path exceeds maximum length of %d characters", MaxPathLength)
+	}
+	if err := fs.checkMimeType(path, content); err != nil {
+		return nil, "", err
+	}
+
+	stored, encoding, err := fs.encodeContent(content)
+	if err != nil {
+		return nil, "", err
+	}
+	clearStorageMetadata(&metadata)
+	if encoding != "" {
+		metadata.ContentEncoding = encoding
+		metadata.UncompressedSize = int64(len(content))
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return stored, string(metadataJSON), nil
+}
+
+// writableSQL matches the rows whose content can be written: symbolic links and directory entries store no file
+// content, so writes to them are rejected with ErrIsSymlink or ErrIsDirectory rather than overwriting the row
+const writableSQL = `COALESCE(json_extract(virtual_filesystem.metadata, '$.mime_type'), '') ` +
+	`NOT IN ('` + SymlinkMimeType + `', 'directory')`
+
+// unwritableErr returns why a write to path matched no writable row: the file is missing, or is a symbolic link or
+// directory. It returns nil if a writable file exists at path.
+func (fs *TursoFileSystem) unwritableErr(ctx context.Context, path string) error {
+	var mimeType sql.NullString
+	err := fs.db.QueryRowContext(ctx, `
+		SELECT json_extract(metadata, '$.mime_type') FROM virtual_filesystem WHERE path = ?
+	`, path).Scan(&mimeType)
+	if err == sql.ErrNoRows {
+		return errors.New("file not found")
+	}
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return writeRejection(mimeType.String, path)
+}
+
+// writeRejection returns the error for a write to the content of the file at path with the given MIME type, or nil if
+// its content can be written
+func writeRejection(mimeType, path string) error {
+	switch mimeType {
+	case SymlinkMimeType:
+		return fmt.Errorf("%w: %s", ErrIsSymlink, path)
+	case "directory":
+		return fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+	return nil
+}
+
+// isUniqueConstraintErr reports whether err is a SQLite UNIQUE constraint violation. The libsql driver only surfaces
+// these as error text.
+func isUniqueConstraintErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// isNoSuchTableErr reports whether err is SQLite's error for a query on a table that has not been created
+func isNoSuchTableErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no such table")
 }
 
 type ComputerUseContext struct {
@@ -155,12 +404,30 @@ func (ctx *ComputerUseContext) HandleOperation(op string, args map[string]interf
 	return nil, nil
 }
 
-// ReadFile retrieves a file from the virtual filesystem
+// ReadFile retrieves a file from the virtual filesystem, following symbolic links
 func (fs *TursoFileSystem) ReadFile(path string) (*VirtualFile, error) {
+	return fs.ReadFileContext(context.Background(), path)
+}
+
+// ReadFileContext is like ReadFile but runs its queries under ctx.
+func (fs *TursoFileSystem) ReadFileContext(ctx context.Context, path string) (*VirtualFile, error) {
+	return fs.readFileFollowingLinks(ctx, path)
+}
+
+// readFile retrieves the row at path as is, without following symbolic links
+func (fs *TursoFileSystem) readFile(ctx context.Context, path string) (*VirtualFile, error) {
+	if fs.readCache != nil {
+		return fs.readFileCached(ctx, path)
+	}
+	return fs.queryFile(ctx, path)
+}
+
+// queryFile reads the row at path from the database
+func (fs *TursoFileSystem) queryFile(ctx context.Context, path string) (*VirtualFile, error) {
 	var file VirtualFile
 	var metadataStr string
 
-	err := fs.db.QueryRow(`
+	err := fs.db.QueryRowContext(ctx, `
 		SELECT id, path, content, metadata, created_at, updated_at 
 		FROM virtual_filesystem 
 		WHERE path = ?
@@ -184,17 +451,36 @@ func (fs *TursoFileSystem) ReadFile(path string) (*VirtualFile, error) {
 	if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
 		return nil, fmt.Errorf("metadata parse error: %w", err)
 	}
+	if err := decodeContent(&file); err != nil {
+		return nil, err
+	}
+	markSymlink(&file)
 
 	return &file, nil
 }
 
-// UpdateFile modifies an existing file's content
+// UpdateFile modifies an existing file's content. Writes do not follow symbolic links: if path is a symbolic link it
+// fails with ErrIsSymlink, and with ErrIsDirectory if it is a directory, leaving the entry untouched.
 func (fs *TursoFileSystem) UpdateFile(path string, content []byte) error {
-	result, err := fs.db.Exec(`
+	return fs.UpdateFileContext(context.Background(), path, content)
+}
+
+// UpdateFileContext is like UpdateFile but runs its queries under ctx.
+func (fs *TursoFileSystem) UpdateFileContext(ctx context.Context, path string, content []byte) error {
+	if err := fs.checkMimeType(path, content); err != nil {
+		return err
+	}
+
+	stored, encoding, err := fs.encodeContent(content)
+	if err != nil {
+		return err
+	}
+
+	result, err := fs.db.ExecContext(ctx, `
 		UPDATE virtual_filesystem 
-		SET content = ?, updated_at = CURRENT_TIMESTAMP 
-		WHERE path = ?
-	`, content, path)
+		SET content = ?, metadata = json_patch(metadata, ?), updated_at = CURRENT_TIMESTAMP 
+		WHERE path = ? AND `+writableSQL+`
+	`, stored, encodingPatch(encoding, len(content)), path)
 
 	if err != nil {
 		return fmt.Errorf("update failed: %w", err)
@@ -205,15 +491,24 @@ func (fs *TursoFileSystem) UpdateFile(path string, content []byte) error {
 		return fmt.Errorf("error checking update result: %w", err)
 	}
 	if rows == 0 {
+		if err := fs.unwritableErr(ctx, path); err != nil {
+			return err
+		}
 		return errors.New("file not found")
 	}
 
+	fs.publish(FileUpdated, path)
 	return nil
 }
 
 // DeleteFile removes a file from the virtual filesystem
 func (fs *TursoFileSystem) DeleteFile(path string) error {
-	result, err := fs.db.Exec(`
+	return fs.DeleteFileContext(context.Background(), path)
+}
+
+// DeleteFileContext is like DeleteFile but runs its queries under ctx.
+func (fs *TursoFileSystem) DeleteFileContext(ctx context.Context, path string) error {
+	result, err := fs.db.ExecContext(ctx, `
 		DELETE FROM virtual_filesystem 
 		WHERE path = ?
 	`, path)
@@ -230,17 +525,23 @@ func (fs *TursoFileSystem) DeleteFile(path string) error {
 		return errors.New("file not found")
 	}
 
+	fs.publish(FileDeleted, path)
 	return nil
 }
 
 // ListFiles retrieves all files in a directory
 func (fs *TursoFileSystem) ListFiles(path string) ([]VirtualFile, error) {
+	return fs.ListFilesContext(context.Background(), path)
+}
+
+// ListFilesContext is like ListFiles but runs its queries under ctx.
+func (fs *TursoFileSystem) ListFilesContext(ctx context.Context, path string) ([]VirtualFile, error) {
 	// Ensure path ends with / for directory matching
 	if !strings.HasSuffix(path, "/") {
 		path += "/"
 	}
 
-	rows, err := fs.db.Query(`
+	rows, err := fs.db.QueryContext(ctx, `
 		SELECT id, path, content, metadata, created_at, updated_at 
 		FROM virtual_filesystem 
 		WHERE path LIKE ? || '%'
@@ -271,6 +572,10 @@ func (fs *TursoFileSystem) ListFiles(path string) ([]VirtualFile, error) {
 		if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
 			return nil, fmt.Errorf("metadata parse error: %w", err)
 		}
+		if err := decodeContent(&file); err != nil {
+			return nil, err
+		}
+		markSymlink(&file)
 
 		files = append(files, file)
 	}
@@ -278,8 +583,43 @@ func (fs *TursoFileSystem) ListFiles(path string) ([]VirtualFile, error) {
 	return files, nil
 }
 
+// DirStats counts the files and directory entries below prefix and sums the size of their content, in a single
+// aggregate query over the path index. The directory entry for prefix itself is not counted; symbolic links count
+// as files. An empty prefix covers the whole filesystem.
+func (fs *TursoFileSystem) DirStats(prefix string) (fileCount int, dirCount int, totalBytes int64, err error) {
+	return fs.DirStatsContext(context.Background(), prefix)
+}
+
+// DirStatsContext is like DirStats but runs its queries under ctx.
+func (fs *TursoFileSystem) DirStatsContext(ctx context.Context, prefix string) (fileCount int, dirCount int, totalBytes int64, err error) {
+	// Ensure prefix ends with / for directory matching
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	err = fs.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN json_extract(metadata, '$.mime_type') = 'directory' THEN 0 ELSE 1 END), 0),
+			COALESCE(SUM(CASE WHEN json_extract(metadata, '$.mime_type') = 'directory' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(`+storedSizeSQL+`), 0)
+		FROM virtual_filesystem
+		WHERE path LIKE ? || '%' ESCAPE '\' AND path <> ?
+	`, escapeLike(prefix), prefix).Scan(&fileCount, &dirCount, &totalBytes)
+
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("directory stats query failed: %w", err)
+	}
+
+	return fileCount, dirCount, totalBytes, nil
+}
+
 // CreateDirectory creates a new directory entry
 func (fs *TursoFileSystem) CreateDirectory(path string) error {
+	return fs.CreateDirectoryContext(context.Background(), path)
+}
+
+// CreateDirectoryContext is like CreateDirectory but runs its queries under ctx.
+func (fs *TursoFileSystem) CreateDirectoryContext(ctx context.Context, path string) error {
 	// Ensure path ends with /
 	if !strings.HasSuffix(path, "/") {
 		path += "/"
@@ -296,70 +636,51 @@ func (fs *TursoFileSystem) CreateDirectory(path string) error {
 		return fmt.Errorf("metadata marshaling failed: %w", err)
 	}
 
-	_, err = fs.db.Exec(`
+	_, err = fs.db.ExecContext(ctx, `
 		INSERT INTO virtual_filesystem (id, path, metadata)
 		VALUES (?, ?, ?)
-	`, generateUUID(), path, metadataJSON)
+	`, generateUUID(), path, string(metadataJSON))
 
 	if err != nil {
 		return fmt.Errorf("directory creation failed: %w", err)
 	}
 
+	fs.publish(FileCreated, path)
 	return nil
 }
 
 // SearchFiles searches for files matching the query
 func (fs *TursoFileSystem) SearchFiles(query string) ([]VirtualFile, error) {
-	rows, err := fs.db.Query(`
-		SELECT id, path, content, metadata, created_at, updated_at 
-		FROM virtual_filesystem 
-		WHERE path LIKE ? OR metadata LIKE ?
-	`, "%"+query+"%", "%"+query+"%")
-
-	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
-	defer rows.Close()
-
-	var files []VirtualFile
-	for rows.Next() {
-		var file VirtualFile
-		var metadataStr string
-
-		err := rows.Scan(
-			&file.ID,
-			&file.Path,
-			&file.Content,
-			&metadataStr,
-			&file.CreatedAt,
-			&file.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("row scan failed: %w", err)
-		}
-
-		if err := json.Unmarshal([]byte(metadataStr), &file.Metadata); err != nil {
-			return nil, fmt.Errorf("metadata parse error: %w", err)
-		}
-
-		files = append(files, file)
-	}
+	return fs.SearchFilesContext(context.Background(), query)
+}
 
-	return files, nil
+// SearchFilesContext is like SearchFiles but runs its queries under ctx.
+func (fs *TursoFileSystem) SearchFilesContext(ctx context.Context, query string) ([]VirtualFile, error) {
+	return fs.SearchFilesOptsContext(ctx, query, SearchOptions{IncludeContent: true})
 }
 
 // UpdateMetadata updates a file's metadata
 func (fs *TursoFileSystem) UpdateMetadata(path string, metadata Metadata) error {
+	return fs.UpdateMetadataContext(context.Background(), path, metadata)
+}
+
+// UpdateMetadataContext is like UpdateMetadata but runs its queries under ctx.
+func (fs *TursoFileSystem) UpdateMetadataContext(ctx context.Context, path string, metadata Metadata) error {
+	clearStorageMetadata(&metadata)
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
 		return fmt.Errorf("metadata marshaling failed: %w", err)
 	}
 
-	result, err := fs.db.Exec(`
+	// The storage details of the content are kept from the existing metadata
+	result, err := fs.db.ExecContext(ctx, `
 		UPDATE virtual_filesystem 
-		SET metadata = ?, updated_at = CURRENT_TIMESTAMP 
+		SET metadata = json_patch(?, json_object(
+				'content_encoding', json_extract(metadata, '$.content_encoding'),
+				'uncompressed_size', json_extract(metadata, '$.uncompressed_size'))),
+			updated_at = CURRENT_TIMESTAMP 
 		WHERE path = ?
-	`, metadataJSON, path)
+	`, string(metadataJSON), path)
 
 	if err != nil {
 		return fmt.Errorf("metadata update failed: %w", err)
@@ -373,13 +694,19 @@ func (fs *TursoFileSystem) UpdateMetadata(path string, metadata Metadata) error
 		return errors.New("file not found")
 	}
 
+	fs.publish(FileUpdated, path)
 	return nil
 }
 
 // GetMetadata retrieves a file's metadata
 func (fs *TursoFileSystem) GetMetadata(path string) (Metadata, error) {
+	return fs.GetMetadataContext(context.Background(), path)
+}
+
+// GetMetadataContext is like GetMetadata but runs its queries under ctx.
+func (fs *TursoFileSystem) GetMetadataContext(ctx context.Context, path string) (Metadata, error) {
 	var metadataStr string
-	err := fs.db.QueryRow(`
+	err := fs.db.QueryRowContext(ctx, `
 		SELECT metadata 
 		FROM virtual_filesystem 
 		WHERE path = ?
@@ -396,10 +723,69 @@ func (fs *TursoFileSystem) GetMetadata(path string) (Metadata, error) {
 	if err := json.Unmarshal([]byte(metadataStr), &metadata); err != nil {
 		return Metadata{}, fmt.Errorf("metadata parse error: %w", err)
 	}
+	clearStorageMetadata(&metadata)
 
 	return metadata, nil
 }
 
+// StatFile returns a file's information and size without reading its content, following symbolic links like ReadFile
+func (fs *TursoFileSystem) StatFile(path string) (*FileInfo, error) {
+	return fs.StatFileContext(context.Background(), path)
+}
+
+// StatFileContext is like StatFile but runs its queries under ctx.
+func (fs *TursoFileSystem) StatFileContext(ctx context.Context, path string) (*FileInfo, error) {
+	for depth := 0; ; depth++ {
+		info, err := fs.statFile(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Metadata.MimeType != SymlinkMimeType {
+			return info, nil
+		}
+		if depth >= MaxSymlinkDepth {
+			return nil, fmt.Errorf("%w: %s", ErrSymlinkLoop, path)
+		}
+		// A link's content is only its target path, so reading it is cheap
+		if path, err = fs.ReadLinkContext(ctx, path); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// statFile retrieves the information of the row at path without its content or following symbolic links
+func (fs *TursoFileSystem) statFile(ctx context.Context, path string) (*FileInfo, error) {
+	var info FileInfo
+	var metadataStr string
+
+	err := fs.db.QueryRowContext(ctx, `
+		SELECT id, path, `+storedSizeSQL+`, metadata, created_at, updated_at
+		FROM virtual_filesystem
+		WHERE path = ?
+	`, path).Scan(
+		&info.ID,
+		&info.Path,
+		&info.Size,
+		&metadataStr,
+		&info.CreatedAt,
+		&info.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(metadataStr), &info.Metadata); err != nil {
+		return nil, fmt.Errorf("metadata parse error: %w", err)
+	}
+	clearStorageMetadata(&info.Metadata)
+
+	return &info, nil
+}
+
 // ComputerUseContext handler implementations
 func (ctx *ComputerUseContext) handleWriteFile(args map[string]interface{}) (interface{}, error) {
 	path, ok := args["path"].(string)
@@ -416,19 +802,14 @@ func (ctx *ComputerUseContext) handleWriteFile(args map[string]interface{}) (int
 		}
 	}
 
-	_, err := ctx.fs.ReadFile(path)
-	if err == nil {
-		return nil, ctx.fs.UpdateFile(path, content)
-	}
-
-	// File doesn't exist, create it
+	// Created files get fresh metadata; existing files keep theirs and only have their content replaced
 	metadata := Metadata{
-		MimeType:    detectMimeType(path, content),
+		MimeType:    ctx.fs.DetectMimeType(path, content),
 		Tags:        []string{},
 		Permissions: map[string]string{"access": "rw"},
 	}
 
-	return nil, ctx.fs.CreateFile(path, content, metadata)
+	return nil, ctx.fs.UpsertFile(path, content, metadata)
 }
 
 func (ctx *ComputerUseContext) handleReadFile(args map[string]interface{}) (interface{}, error) {
@@ -440,21 +821,23 @@ func (ctx *ComputerUseContext) handleReadFile(args map[string]interface{}) (inte
 	return ctx.fs.ReadFile(path)
 }
 
-// Helper function to detect MIME type based on file extension and content
-func detectMimeType(path string, content []byte) string {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".txt":
-		return "text/plain"
-	case ".json":
-		return "application/json"
-	case ".md":
-		return "text/markdown"
-	case ".html":
-		return "text/html"
-	default:
+// Helper function to detect MIME type based on file extension, looked up in mimeTypes, and content. Executables are
+// recognised by their magic bytes whatever their extension, so they cannot be disguised by renaming; unknown
+// extensions fall back to sniffing the content.
+func detectMimeType(path string, content []byte, mimeTypes map[string]string) string {
+	if mimeType, ok := sniffExecutable(content); ok {
+		return mimeType
+	}
+
+	if mimeType, ok := mimeTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return mimeType
+	}
+
+	if len(content) == 0 {
 		return "application/octet-stream"
 	}
+	mimeType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	return mimeType
 }
 
 func generateUUID() string {
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestFileSystem(t *testing.T) *TursoFileSystem {
+	t.Helper()
+	db, err := getTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewTursoFileSystemFromDB(db)
+}
+
+func TestFileLifecycle(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	metadata := Metadata{MimeType: "text/plain", Tags: []string{"notes"}}
+	if err := fs.CreateFile("/docs/a.txt", []byte("hello"), metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateFile("/docs/a.txt", []byte("again"), metadata); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("second create err = %v, want ErrFileExists", err)
+	}
+	if err := fs.UpdateFile("/docs/a.txt", []byte("hello, world")); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := fs.ReadFileString("/docs/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "hello, world" {
+		t.Errorf("content = %q, want %q", text, "hello, world")
+	}
+
+	tagged, err := fs.FilesByTag("notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 1 || tagged[0].Path != "/docs/a.txt" {
+		t.Errorf("FilesByTag = %v, want /docs/a.txt", tagged)
+	}
+
+	if err := fs.DeleteFile("/docs/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile("/docs/a.txt"); err == nil {
+		t.Error("expected an error reading a deleted file")
+	}
+	if tagged, _ := fs.FilesByTag("notes"); len(tagged) != 0 {
+		t.Errorf("FilesByTag after delete = %v, want none", tagged)
+	}
+}
+
+func TestDeleteDirectory(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	for _, path := range []string{"/logs/", "/logs/2024/"} {
+		if err := fs.CreateDirectory(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{"/logs/a.log", "/logs/2024/b.log", "/logs_old/c.log"} {
+		if err := fs.CreateFile(path, []byte("x"), Metadata{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := fs.DeleteDirectory("/logs", false); !errors.Is(err, ErrDirectoryNotEmpty) {
+		t.Fatalf("non-recursive delete err = %v, want ErrDirectoryNotEmpty", err)
+	}
+
+	deleted, err := fs.DeleteDirectory("/logs", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 4 {
+		t.Errorf("deleted = %d, want 4", deleted)
+	}
+	if _, err := fs.ReadFile("/logs_old/c.log"); err != nil {
+		t.Errorf("sibling with a common prefix was deleted: %v", err)
+	}
+}
+
+func TestContextCancelled(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := fs.CreateFileContext(ctx, "/a.txt", []byte("x"), Metadata{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestDirStats(t *testing.T) {
+	fs := newTestFileSystem(t)
+	fs.compressionThreshold = 16
+
+	big := strings.Repeat("compressible ", 100)
+	for path, content := range map[string]string{
+		"/docs/a.txt":     "hello",
+		"/docs/sub/b.txt": "world!",
+		"/docs/big.txt":   big,
+		"/d%_x/c.txt":     "abc",
+		"/dzzzx/e.txt":    "matched only without escaping",
+	} {
+		if err := fs.CreateFile(path, []byte(content), Metadata{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"/docs", "/docs/sub"} {
+		if err := fs.CreateDirectory(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		prefix     string
+		files      int
+		dirs       int
+		totalBytes int64
+	}{
+		// The directory entry of the prefix itself is not counted, and compressed files count at their original size
+		{"/docs", 3, 1, int64(len("hello") + len("world!") + len(big))},
+		{"/docs/sub/", 1, 0, int64(len("world!"))},
+		// % and _ in the prefix match only themselves
+		{"/d%_x", 1, 0, 3},
+		{"", 5, 2, int64(len("hello") + len("world!") + len(big) + 3 + len("matched only without escaping"))},
+	}
+	for _, tt := range tests {
+		files, dirs, totalBytes, err := fs.DirStats(tt.prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files != tt.files || dirs != tt.dirs || totalBytes != tt.totalBytes {
+			t.Errorf("DirStats(%q) = (%d, %d, %d), want (%d, %d, %d)",
+				tt.prefix, files, dirs, totalBytes, tt.files, tt.dirs, tt.totalBytes)
+		}
+	}
+
+	var encoding sql.NullString
+	err := fs.db.QueryRow(`SELECT json_extract(metadata, '$.content_encoding') FROM virtual_filesystem WHERE path = ?`,
+		"/docs/big.txt").Scan(&encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encoding.Valid {
+		t.Error("/docs/big.txt was not stored compressed")
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SymlinkMimeType marks a virtual_filesystem row as a symbolic link. The link's target path is stored as its content.
+const SymlinkMimeType = "inode/symlink"
+
+var (
+	// ErrNotSymlink is returned by ReadLink when the path is not a symbolic link.
+	ErrNotSymlink = errors.New("not a symbolic link")
+	// ErrSymlinkLoop is returned by ReadFile when resolving a path follows more than MaxSymlinkDepth links.
+	ErrSymlinkLoop = errors.New("too many levels of symbolic links")
+	// ErrIsSymlink is returned by writes to the content of a symbolic link. Unlike reads, writes do not follow links,
+	// since that would replace the stored target.
+	ErrIsSymlink = errors.New("is a symbolic link")
+)
+
+// IsSymlink reports whether the file is a symbolic link
+func (f *VirtualFile) IsSymlink() bool {
+	return f.Metadata.MimeType == SymlinkMimeType
+}
+
+// CreateSymlink creates a symbolic link at linkPath pointing at targetPath, so that the same content can be reached
+// through several paths without being stored twice. The target does not need to exist yet; reading a dangling link
+// fails as reading the missing target would. Writes are not followed: writing the content of a link fails with
+// ErrIsSymlink. It fails with ErrFileExists if linkPath is already taken.
+func (fs *TursoFileSystem) CreateSymlink(linkPath, targetPath string) error {
+	return fs.CreateSymlinkContext(context.Background(), linkPath, targetPath)
+}
+
+// CreateSymlinkContext is like CreateSymlink but runs its queries under ctx.
+func (fs *TursoFileSystem) CreateSymlinkContext(ctx context.Context, linkPath, targetPath string) error {
+	if targetPath == "" {
+		return errors.New("symlink target must not be empty")
+	}
+	if len(linkPath) > MaxPathLength || len(targetPath) > MaxPathLength {
+		return fmt.Errorf("path exceeds maximum length of %d characters", MaxPathLength)
+	}
+
+	metadata := Metadata{
+		MimeType:    SymlinkMimeType,
+		Tags:        []string{"symlink"},
+		Permissions: map[string]string{"type": "symlink"},
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("metadata marshaling failed: %w", err)
+	}
+
+	_, err = fs.db.ExecContext(ctx, `
+		INSERT INTO virtual_filesystem (id, path, content, metadata)
+		VALUES (?, ?, ?, ?)
+	`, generateUUID(), linkPath, []byte(targetPath), string(metadataJSON))
+
+	if isUniqueConstraintErr(err) {
+		return fmt.Errorf("%w: %s", ErrFileExists, linkPath)
+	}
+	if err != nil {
+		return fmt.Errorf("symlink creation failed: %w", err)
+	}
+
+	fs.publish(FileCreated, linkPath)
+	return nil
+}
+
+// ReadLink returns the target of the symbolic link at path without following it
+func (fs *TursoFileSystem) ReadLink(path string) (string, error) {
+	return fs.ReadLinkContext(context.Background(), path)
+}
+
+// ReadLinkContext is like ReadLink but runs its queries under ctx.
+func (fs *TursoFileSystem) ReadLinkContext(ctx context.Context, path string) (string, error) {
+	file, err := fs.readFile(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if !file.IsSymlink() {
+		return "", fmt.Errorf("%w: %s", ErrNotSymlink, path)
+	}
+	return file.LinkTarget, nil
+}
+
+// readFileFollowingLinks reads the file at path, following up to MaxSymlinkDepth symbolic links. The returned file is
+// the final target, so its Path is the resolved path.
+func (fs *TursoFileSystem) readFileFollowingLinks(ctx context.Context, path string) (*VirtualFile, error) {
+	for depth := 0; ; depth++ {
+		file, err := fs.readFile(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if !file.IsSymlink() {
+			return file, nil
+		}
+		if depth >= MaxSymlinkDepth {
+			return nil, fmt.Errorf("%w: %s", ErrSymlinkLoop, path)
+		}
+		path = file.LinkTarget
+	}
+}
+
+// markSymlink moves a symbolic link's target from its content to LinkTarget, so that links are listed distinctly
+// from the files they point at
+func markSymlink(file *VirtualFile) {
+	if !file.IsSymlink() {
+		return
+	}
+	file.LinkTarget = string(file.Content)
+	file.Content = nil
+}
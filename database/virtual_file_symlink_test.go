@@ -0,0 +1,105 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateSymlink(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/docs/a.txt", []byte("hello"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateSymlink("/latest", "/docs/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateSymlink("/current", "/latest"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading follows chains of links to the final target
+	file, err := fs.ReadFile("/current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(file.Content) != "hello" || file.Path != "/docs/a.txt" {
+		t.Errorf("read through links = %q at %s, want %q at /docs/a.txt", file.Content, file.Path, "hello")
+	}
+	target, err := fs.ReadLink("/current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "/latest" {
+		t.Errorf("ReadLink = %q, want %q", target, "/latest")
+	}
+	if _, err := fs.ReadLink("/docs/a.txt"); !errors.Is(err, ErrNotSymlink) {
+		t.Errorf("ReadLink of a file = %v, want ErrNotSymlink", err)
+	}
+
+	if err := fs.CreateSymlink("/latest", "/elsewhere"); !errors.Is(err, ErrFileExists) {
+		t.Errorf("symlink over an existing path = %v, want ErrFileExists", err)
+	}
+
+	// A dangling link fails like its missing target, and a cycle fails instead of looping
+	if err := fs.CreateSymlink("/dangling", "/missing.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile("/dangling"); err == nil {
+		t.Error("read of a dangling link succeeded")
+	}
+	if err := fs.CreateSymlink("/loop-a", "/loop-b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateSymlink("/loop-b", "/loop-a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile("/loop-a"); !errors.Is(err, ErrSymlinkLoop) {
+		t.Errorf("read of a link cycle = %v, want ErrSymlinkLoop", err)
+	}
+}
+
+func TestWritesThroughSymlinkRejected(t *testing.T) {
+	fs := newTestFileSystem(t)
+	if err := fs.CreateFile("/a.txt", []byte("target"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateSymlink("/l", "/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	lease, err := fs.AcquireLock("/l", "alice", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writes := map[string]func() error{
+		"UpdateFile":          func() error { return fs.UpdateFile("/l", []byte("new")) },
+		"UpsertFile":          func() error { return fs.UpsertFile("/l", []byte("new"), Metadata{}) },
+		"UpdateFileWithLease": func() error { return fs.UpdateFileWithLease("/l", lease, []byte("new")) },
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, ErrIsSymlink) {
+			t.Errorf("%s through a link = %v, want ErrIsSymlink", name, err)
+		}
+	}
+
+	// Neither the link nor its target changed
+	target, err := fs.ReadLink("/l")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "/a.txt" {
+		t.Errorf("link target = %q, want %q", target, "/a.txt")
+	}
+	file, err := fs.ReadFile("/l")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(file.Content) != "target" {
+		t.Errorf("content through the link = %q, want %q", file.Content, "target")
+	}
+
+	if err := fs.UpdateFile("/missing.txt", []byte("new")); err == nil || errors.Is(err, ErrIsSymlink) {
+		t.Errorf("UpdateFile of a missing file = %v, want file not found", err)
+	}
+}
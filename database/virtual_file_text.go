@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html/charset"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrBinaryContent is returned by ReadFileString for files whose MIME type is not textual, unless the file system was
+// created with WithRawBinaryStrings.
+var ErrBinaryContent = errors.New("file content is binary")
+
+// textMimeTypes are the textual MIME types outside text/* and the +json and +xml suffixes
+var textMimeTypes = map[string]bool{
+	"application/json":       true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"application/x-sh":       true,
+	"application/yaml":       true,
+	"application/x-yaml":     true,
+	"application/toml":       true,
+	"application/sql":        true,
+}
+
+// WithRawBinaryStrings makes ReadFileString return the content of binary files unchanged instead of failing with
+// ErrBinaryContent.
+func WithRawBinaryStrings() FileSystemOption {
+	return func(fs *TursoFileSystem) {
+		fs.rawBinaryStrings = true
+	}
+}
+
+// ReadFileString reads a text file like ReadFile and returns its content as a string, decoded from the charset
+// parameter of its MIME type (e.g. "text/plain; charset=windows-1252") or as UTF-8 if none is recorded. Files with a
+// binary MIME type fail with ErrBinaryContent; files without a MIME type count as text when they are valid UTF-8.
+func (fs *TursoFileSystem) ReadFileString(path string) (string, error) {
+	return fs.ReadFileStringContext(context.Background(), path)
+}
+
+// ReadFileStringContext is like ReadFileString but runs its queries under ctx.
+func (fs *TursoFileSystem) ReadFileStringContext(ctx context.Context, path string) (string, error) {
+	file, err := fs.ReadFileContext(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	return fileText(file, fs.rawBinaryStrings)
+}
+
+// fileText decodes the content of file according to its MIME type, returning binary content as is only if allowBinary
+func fileText(file *VirtualFile, allowBinary bool) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(file.Metadata.MimeType)
+	if err != nil {
+		// No usable MIME type was recorded, so the content has to speak for itself
+		if utf8.Valid(file.Content) {
+			return trimBOM(string(file.Content)), nil
+		}
+		return binaryText(file, allowBinary)
+	}
+
+	if name := params["charset"]; name != "" {
+		enc, _ := charset.Lookup(name)
+		if enc == nil {
+			return "", fmt.Errorf("unknown charset %q for %s", name, file.Path)
+		}
+		decoded, err := enc.NewDecoder().Bytes(file.Content)
+		if err != nil {
+			return "", fmt.Errorf("error decoding %s from %s: %w", file.Path, name, err)
+		}
+		return trimBOM(string(decoded)), nil
+	}
+
+	if !isTextMimeType(mediaType) {
+		return binaryText(file, allowBinary)
+	}
+	return trimBOM(string(file.Content)), nil
+}
+
+func binaryText(file *VirtualFile, allowBinary bool) (string, error) {
+	if !allowBinary {
+		return "", fmt.Errorf("%w: %s is %s", ErrBinaryContent, file.Path, file.Metadata.MimeType)
+	}
+	return string(file.Content), nil
+}
+
+// trimBOM drops a leading UTF-8 byte order mark, which is not part of the text
+func trimBOM(text string) string {
+	return strings.TrimPrefix(text, "\ufeff")
+}
+
+func isTextMimeType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		textMimeTypes[mediaType]
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFileText(t *testing.T) {
+	tests := []struct {
+		name        string
+		mimeType    string
+		content     []byte
+		allowBinary bool
+		want        string
+		wantErr     error
+	}{
+		{"plain text", "text/plain", []byte("hello"), false, "hello", nil},
+		{"json", "application/json", []byte(`{"a":1}`), false, `{"a":1}`, nil},
+		{"suffix", "application/ld+json", []byte(`{}`), false, `{}`, nil},
+		{"declared charset", "text/plain; charset=windows-1252", []byte("caf\xe9"), false, "café", nil},
+		{"byte order mark", "text/plain", []byte("\xef\xbb\xbfhi"), false, "hi", nil},
+		{"no mime type utf8", "", []byte("notes"), false, "notes", nil},
+		{"no mime type binary", "", []byte{0xff, 0xfe, 0x00}, false, "", ErrBinaryContent},
+		{"binary", "image/png", []byte("\x89PNG"), false, "", ErrBinaryContent},
+		{"binary allowed", "image/png", []byte("\x89PNG"), true, "\x89PNG", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &VirtualFile{Path: "/f", Content: tt.content, Metadata: Metadata{MimeType: tt.mimeType}}
+			got, err := fileText(file, tt.allowBinary)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	file := &VirtualFile{Path: "/f", Content: []byte("x"), Metadata: Metadata{MimeType: "text/plain; charset=nonsense"}}
+	if _, err := fileText(file, false); err == nil {
+		t.Error("expected an error for an unknown charset")
+	}
+}
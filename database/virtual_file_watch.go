@@ -0,0 +1,87 @@
+package database
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// FileOperation is the kind of change a FileEvent reports
+type FileOperation string
+
+const (
+	FileCreated FileOperation = "create"
+	FileUpdated FileOperation = "update"
+	FileDeleted FileOperation = "delete"
+)
+
+// FileEvent reports a change to the file, directory entry or symlink at Path. Content and metadata changes are both
+// reported as FileUpdated.
+type FileEvent struct {
+	Path string
+	Op   FileOperation
+}
+
+// watchBufferSize is the number of events a watcher can fall behind by before further events for it are dropped
+const watchBufferSize = 256
+
+// watchRegistry holds the watchers of a TursoFileSystem. Its zero value is ready to use.
+type watchRegistry struct {
+	mu       sync.Mutex
+	nextID   int
+	watchers map[int]*fileWatcher
+}
+
+type fileWatcher struct {
+	prefix string
+	events chan FileEvent
+}
+
+// Watch returns a channel receiving an event for every change made through this TursoFileSystem to a path starting
+// with pathPrefix ("" or "/" watches everything), and a function that stops watching and closes the channel. Only
+// changes made in this process are seen, since the database does not push changes. Events are sent without blocking
+// the write that caused them, so a watcher that falls more than a few hundred events behind misses the newer ones.
+func (fs *TursoFileSystem) Watch(pathPrefix string) (<-chan FileEvent, func()) {
+	w := &fileWatcher{prefix: pathPrefix, events: make(chan FileEvent, watchBufferSize)}
+
+	fs.watches.mu.Lock()
+	if fs.watches.watchers == nil {
+		fs.watches.watchers = make(map[int]*fileWatcher)
+	}
+	id := fs.watches.nextID
+	fs.watches.nextID++
+	fs.watches.watchers[id] = w
+	fs.watches.mu.Unlock()
+
+	var once sync.Once
+	return w.events, func() {
+		once.Do(func() {
+			fs.watches.mu.Lock()
+			delete(fs.watches.watchers, id)
+			fs.watches.mu.Unlock()
+			close(w.events)
+		})
+	}
+}
+
+// publish notifies the watchers of each of paths that op was applied to it. Every successful write publishes, so this
+// is also where the paths are dropped from the read cache.
+func (fs *TursoFileSystem) publish(op FileOperation, paths ...string) {
+	fs.readCache.invalidate(paths...)
+
+	fs.watches.mu.Lock()
+	defer fs.watches.mu.Unlock()
+
+	for _, w := range fs.watches.watchers {
+		for _, path := range paths {
+			if !strings.HasPrefix(path, w.prefix) {
+				continue
+			}
+			select {
+			case w.events <- FileEvent{Path: path, Op: op}:
+			default:
+				slog.Warn("Dropping file event for a watcher that is falling behind", "path", path, "op", op)
+			}
+		}
+	}
+}
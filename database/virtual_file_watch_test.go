@@ -0,0 +1,108 @@
+package database
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestWatch(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	events, stop := fs.Watch("/docs/")
+	all, stopAll := fs.Watch("")
+	defer stopAll()
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"create", func() error { return fs.CreateFile("/docs/a.txt", []byte("a"), Metadata{}) }},
+		{"upsert new", func() error { return fs.UpsertFile("/docs/b.txt", []byte("b"), Metadata{}) }},
+		{"upsert existing", func() error { return fs.UpsertFile("/docs/b.txt", []byte("b2"), Metadata{}) }},
+		{"update", func() error { return fs.UpdateFile("/docs/a.txt", []byte("a2")) }},
+		{"atomic write", func() error { return fs.AtomicWrite("/docs/a.txt", []byte("a3"), Metadata{}) }},
+		{"metadata", func() error { return fs.UpdateMetadata("/docs/a.txt", Metadata{Tags: []string{"x"}}) }},
+		{"outside prefix", func() error { return fs.CreateFile("/notes/c.txt", []byte("c"), Metadata{}) }},
+		{"delete", func() error { return fs.DeleteFile("/docs/b.txt") }},
+		{"delete directory", func() error { _, err := fs.DeleteDirectory("/docs", true); return err }},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+	}
+	if err := fs.UpdateFile("/docs/missing.txt", []byte("x")); err == nil {
+		t.Fatal("update of a missing file succeeded")
+	}
+
+	stop()
+	stop()
+	if err := fs.CreateFile("/docs/d.txt", []byte("d"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []FileEvent
+	for event := range events {
+		got = append(got, event)
+	}
+	want := []FileEvent{
+		{"/docs/a.txt", FileCreated},
+		{"/docs/b.txt", FileCreated},
+		{"/docs/b.txt", FileUpdated},
+		{"/docs/a.txt", FileUpdated},
+		{"/docs/a.txt", FileUpdated},
+		{"/docs/a.txt", FileUpdated},
+		{"/docs/b.txt", FileDeleted},
+		{"/docs/a.txt", FileDeleted},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	if n := len(all); n != len(want)+2 {
+		t.Errorf("unfiltered watcher got %d events, want %d", n, len(want)+2)
+	}
+}
+
+func TestWatchImportTar(t *testing.T) {
+	src := newTestFileSystem(t)
+	for _, path := range []string{"/a.txt", "/b.txt"} {
+		if err := src.CreateFile(path, []byte(path), Metadata{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var archive bytes.Buffer
+	if err := src.ExportTar(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := newTestFileSystem(t)
+	WithReadCache(10)(fs)
+	if err := fs.CreateFile("/a.txt", []byte("old"), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile("/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	events, stop := fs.Watch("")
+	if err := fs.ImportTar(&archive); err != nil {
+		t.Fatal(err)
+	}
+	stop()
+
+	var got []FileEvent
+	for event := range events {
+		got = append(got, event)
+	}
+	want := []FileEvent{{"/b.txt", FileCreated}, {"/a.txt", FileUpdated}}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	// The import dropped the cached copy of the file it replaced
+	if content, err := fs.ReadFileString("/a.txt"); err != nil || content != "/a.txt" {
+		t.Errorf("ReadFileString after import = %q, %v; want the imported content", content, err)
+	}
+}
@@ -28,19 +28,46 @@ type DebugContext struct {
 	data map[interface{}]interface{}
 }
 
+// WithValue returns a derived DebugContext carrying key/val. The derived context gets its own copy of the recorded
+// values, so setting a value on a child never changes what the parent (or a sibling) sees.
 func (d *DebugContext) WithValue(key, val interface{}) *DebugContext {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	if d.data == nil {
-		d.data = make(map[interface{}]interface{})
+	data := make(map[interface{}]interface{}, len(d.data)+1)
+	for k, v := range d.data {
+		data[k] = v
 	}
-	d.data[key] = val
+	data[key] = val
 
 	return &DebugContext{
 		Context: context.WithValue(d.Context, key, val),
-		data:    d.data,
+		data:    data,
+	}
+}
+
+// Get returns the value recorded for key on this DebugContext, and whether it was present.
+func (d *DebugContext) Get(key interface{}) (interface{}, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	val, ok := d.data[key]
+	return val, ok
+}
+
+// DebugValue returns the value recorded for key on ctx as a T. It reports false if the key is missing or the value
+// is not a T.
+func DebugValue[T any](ctx *DebugContext, key interface{}) (T, bool) {
+	var zero T
+	val, ok := ctx.Get(key)
+	if !ok {
+		return zero, false
+	}
+	typed, ok := val.(T)
+	if !ok {
+		return zero, false
 	}
+	return typed, true
 }
 
 func (d *DebugContext) PrintValues() {
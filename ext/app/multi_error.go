@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemError is the error of a single item of a batch or multi-mirror operation, keyed by the item it concerns, such as
+// a URL or a file path.
+type ItemError struct {
+	Key string
+	Err error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError collects the errors of the items that failed in an operation over many items, so a caller can see which
+// ones failed and why instead of only the last error. errors.Is and errors.As match against any contained error. The
+// zero value is ready to use; return it through ErrorOrNil so that no failures means a nil error.
+type MultiError struct {
+	errs []ItemError
+}
+
+// Add records err as the failure of the item identified by key. A nil err is ignored.
+func (m *MultiError) Add(key string, err error) {
+	if err != nil {
+		m.errs = append(m.errs, ItemError{Key: key, Err: err})
+	}
+}
+
+// Errors returns the recorded failures in the order they were added.
+func (m *MultiError) Errors() []ItemError {
+	return append([]ItemError(nil), m.errs...)
+}
+
+// Len returns the number of recorded failures.
+func (m *MultiError) Len() int {
+	return len(m.errs)
+}
+
+// ErrorOrNil returns m, or nil if no failure was recorded.
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.errs) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error lists each failure on its own line as "key: error".
+func (m *MultiError) Error() string {
+	lines := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.errs))
+	for i, err := range m.errs {
+		errs[i] = err
+	}
+	return errs
+}
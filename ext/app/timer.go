@@ -14,3 +14,27 @@ func Since(start time.Time) {
 func LogSince(msg string, start time.Time) {
 	slog.Info(msg, "time", time.Since(start))
 }
+
+// Measure runs fn and returns how long it took to complete.
+func Measure(fn func()) time.Duration {
+	start := time.Now()
+	fn()
+	return time.Since(start)
+}
+
+// Track logs the time since the start time and hands the elapsed duration to record, to be used ergonomically with
+// defer when the duration is needed as a value as well as a log line (e.g. for metrics). record may be nil.
+//
+// Example:
+//
+//	defer app.Track(time.Now(), func(d time.Duration) {
+//	    requestDuration.Observe(d.Seconds())
+//	})
+func Track(start time.Time, record func(time.Duration)) time.Duration {
+	elapsed := time.Since(start)
+	slog.Info("Finished", "time", elapsed)
+	if record != nil {
+		record(elapsed)
+	}
+	return elapsed
+}
@@ -2,6 +2,7 @@ package httpext
 
 import (
 	"errors"
+	"io"
 	"log/slog"
 	"net"
 	"os"
@@ -17,9 +18,10 @@ const BadRequestError = "bad request error"
 //   - Timeout errors (net.Error with Timeout() == true)
 //   - Dial and read operation errors (net.OpError)
 //   - Specific system errors like connection refused, host unreachable, and network unreachable
+//   - Connections dropped mid-transfer (connection reset, broken pipe, unexpected EOF)
 //   - DNS lookup timeout errors (net.DNSError)
 //   - Generic timeout errors (detected by os.IsTimeout)
-//   - String matching for common network error messages
+//   - String matching for common network error messages, including HTTP/2 stream errors
 //
 // This function is useful for determining if an error is likely due to network issues
 // and may be resolved by retrying the operation after a delay.
@@ -53,6 +55,10 @@ func IsDialError(err error) bool {
 		}
 	}
 
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
 	var dnsErr *net.DNSError
 	if errors.As(err, &dnsErr) {
 		slog.Warn("DNS lookup error encountered",
@@ -69,5 +75,6 @@ func IsDialError(err error) bool {
 	errMsg := err.Error()
 	return strings.Contains(errMsg, "network is unreachable") ||
 		strings.Contains(errMsg, "no such host") ||
-		strings.Contains(errMsg, "i/o timeout")
+		strings.Contains(errMsg, "i/o timeout") ||
+		strings.Contains(errMsg, "stream error")
 }
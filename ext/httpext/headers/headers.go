@@ -35,6 +35,29 @@ func RSSFeedHeaders() http.Header {
 	return headers
 }
 
+// Clone returns a deep copy of header that can be modified without affecting header. Unlike http.Header.Clone, it
+// returns an empty header rather than nil for a nil header.
+func Clone(header http.Header) http.Header {
+	if header == nil {
+		return make(http.Header)
+	}
+	return header.Clone()
+}
+
+// Merge returns a new header with the fields of base and overrides, which are both left unchanged. A field present in
+// overrides replaces all values of that field in base; a field overridden with no values is removed. This makes the
+// fixed headers above composable, e.g. Merge(SECBotHeaders(), http.Header{"Host": {"efts.sec.gov"}}).
+func Merge(base http.Header, overrides http.Header) http.Header {
+	merged := Clone(base)
+	for key, values := range overrides {
+		merged.Del(key)
+		for _, value := range values {
+			merged.Add(key, value)
+		}
+	}
+	return merged
+}
+
 /*
 // Modeledge generates and returns HTTP headers specific for the Modeledge website.
 // This might be useful for requests made by or for the Modeledge website.
@@ -0,0 +1,54 @@
+package headers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := SECBotHeaders()
+	base.Add("Accept", "text/html")
+	base.Add("Accept", "application/xhtml+xml")
+
+	merged := Merge(base, http.Header{
+		"Host":            {"efts.sec.gov"},
+		"authorization":   {"Bearer token"},
+		"Accept":          {"application/json"},
+		"Accept-Encoding": nil,
+	})
+
+	if got := merged.Get("Host"); got != "efts.sec.gov" {
+		t.Errorf("Host = %q, want the override", got)
+	}
+	if got := merged.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q, want the added field under its canonical key", got)
+	}
+	if got := merged.Values("Accept"); !slices.Equal(got, []string{"application/json"}) {
+		t.Errorf("Accept = %v, want only the override", got)
+	}
+	if _, ok := merged["Accept-Encoding"]; ok {
+		t.Error("Accept-Encoding was overridden with no values and should be removed")
+	}
+	if got := merged.Get("User-Agent"); got != base.Get("User-Agent") {
+		t.Errorf("User-Agent = %q, want it kept from base", got)
+	}
+
+	merged.Set("User-Agent", "changed")
+	if base.Get("Host") != "www.sec.gov" || base.Get("User-Agent") == "changed" || len(base.Values("Accept")) != 2 {
+		t.Errorf("base was modified: %v", base)
+	}
+}
+
+func TestClone(t *testing.T) {
+	if got := Clone(nil); got == nil {
+		t.Error("Clone(nil) = nil, want an empty header")
+	}
+
+	original := RSSFeedHeaders()
+	clone := Clone(original)
+	clone.Set("Referer", "https://example.com/")
+	if original.Get("Referer") != "https://www.spglobal.com/" {
+		t.Error("modifying the clone changed the original")
+	}
+}
@@ -0,0 +1,26 @@
+package requests
+
+import "time"
+
+// Clock is the source of time for a RetryRequest's backoff and waits. Replacing it, e.g. with a fake that advances
+// instantly, makes the timing of retries testable without real delays. The rate limiter keeps its own real clock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// realClock is the default Clock, backed by the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+// WithClock sets the clock used for retry backoff, the network-unavailable wait and result timestamps. It defaults to
+// the real clock and is meant for tests.
+func WithClock(clock Clock) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.clock = clock
+	}
+}
@@ -0,0 +1,114 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock records every wait and advances its time by it instantly
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Sleep(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+}
+
+func TestBackoffUsesClock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{}
+	r := NewRetryRequest(WithAttemptsAndBackoff(4, time.Second), WithClock(clock))
+
+	start := time.Now()
+	if _, _, err := r.GetResponse(context.Background(), server.URL); err == nil {
+		t.Fatal("expected an error after exhausting the retries")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v of real time", elapsed)
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if len(clock.waits) < len(want) || !slices.Equal(clock.waits[:len(want)], want) {
+		t.Errorf("backoff waits = %v, want %v", clock.waits, want)
+	}
+}
+
+// refusedURL returns the URL of a closed listener, which refuses connections for both requests and network probes
+func refusedURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + listener.Addr().String()
+	listener.Close()
+	return url
+}
+
+func TestNetworkUnavailableWaitUsesClock(t *testing.T) {
+	url := refusedURL(t)
+	tests := []struct {
+		name    string
+		options []RetryRequestOption
+		want    []time.Duration
+	}{
+		{"constant", nil, slices.Repeat([]time.Duration{time.Minute}, 10)},
+		{
+			"growing",
+			[]RetryRequestOption{WithNetworkRecoveryBackOffGrowth(3 * time.Minute)},
+			[]time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute, time.Minute},
+		},
+		{
+			"attempt limit",
+			[]RetryRequestOption{WithNetworkRecoveryMaxAttempts(3)},
+			slices.Repeat([]time.Duration{time.Minute}, 3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{}
+			r := NewRetryRequest(append([]RetryRequestOption{
+				WithAttemptsAndBackoff(1, 0),
+				WithNetworkRetryPolicy(time.Minute, 10*time.Minute),
+				WithNetworkProbe([]string{url}, time.Second),
+				WithClock(clock),
+			}, tt.options...)...)
+
+			_, _, err := r.GetResponse(context.Background(), url)
+			if !errors.Is(err, ErrNetworkUnavailableAfterMaxWait) {
+				t.Fatalf("err = %v, want ErrNetworkUnavailableAfterMaxWait", err)
+			}
+			if !slices.Equal(clock.waits, tt.want) {
+				t.Errorf("network waits = %v, want %v", clock.waits, tt.want)
+			}
+		})
+	}
+}
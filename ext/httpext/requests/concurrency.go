@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"context"
+	"golang.org/x/sync/semaphore"
+	"net/http"
+	"sync"
+)
+
+// WithMaxConcurrency bounds the number of GET and POST requests in flight at once to n. Unlike rate limiting, which
+// bounds how often requests start, this bounds how many run simultaneously. A request holds its slot, through
+// retries, until the CancelFunc returned with its response is called (the GetContents family does this once the
+// body has been read); callers over the limit block until a slot frees up or their context is done.
+func WithMaxConcurrency(n int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.concurrency = semaphore.NewWeighted(int64(n))
+	}
+}
+
+// withConcurrencySlot runs do while holding a concurrency slot, if WithMaxConcurrency is configured. The slot is
+// released by the returned CancelFunc, or immediately if do returns none.
+func (r *RetryRequest) withConcurrencySlot(ctx context.Context, do func() (*http.Response, context.CancelFunc, error)) (*http.Response, context.CancelFunc, error) {
+	if r.concurrency == nil {
+		return do()
+	}
+
+	if err := r.concurrency.Acquire(ctx, 1); err != nil {
+		return nil, nil, &PhaseError{Phase: ErrPhaseSetup, Err: err}
+	}
+
+	resp, cancel, err := do()
+	if cancel == nil {
+		r.concurrency.Release(1)
+		return resp, nil, err
+	}
+
+	var once sync.Once
+	return resp, func() {
+		cancel()
+		once.Do(func() { r.concurrency.Release(1) })
+	}, err
+}
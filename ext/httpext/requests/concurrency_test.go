@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(1, 0), WithMaxConcurrency(2))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrency = %d, want 2", got)
+	}
+
+	// A response holds its slot until its CancelFunc is called, so a request over the limit waits for it
+	first, cancelFirst, err := r.GetResponse(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, cancelSecond, err := r.GetResponse(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := r.GetResponse(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("request over the limit = %v, want context.DeadlineExceeded", err)
+	}
+
+	drainAndCloseBody(first.Body)
+	cancelFirst()
+	third, cancelThird, err := r.GetResponse(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("request after a slot was freed: %v", err)
+	}
+	drainAndCloseBody(third.Body)
+	cancelThird()
+	drainAndCloseBody(second.Body)
+	cancelSecond()
+}
@@ -0,0 +1,52 @@
+package requests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// GetIfModifiedSince sends a conditional GET for url with If-Modified-Since set to since, with the usual retries. A
+// 304 Not Modified response returns modified=false, a nil body and since as lastModified, without downloading
+// anything. Otherwise the decoded body is returned with modified=true and the response's Last-Modified time, which is
+// zero if the server sent none; pass it as since on the next poll. A zero since sends an unconditional request.
+func (r *RetryRequest) GetIfModifiedSince(ctx context.Context, url string, since time.Time) (body []byte, modified bool, lastModified time.Time, err error) {
+	opts := getOptions{acceptNotModified: true}
+	if !since.IsZero() {
+		opts.header = http.Header{"If-Modified-Since": {since.UTC().Format(http.TimeFormat)}}
+	}
+
+	resp, cancel, err := r.withConcurrencySlot(ctx, func() (*http.Response, context.CancelFunc, error) {
+		return r.getResponse(ctx, url, opts)
+	})
+	if cancel != nil {
+		defer cancel()
+	}
+	if err != nil {
+		return nil, false, time.Time{}, fmt.Errorf("failed to get a response for the URL %s: %w", url, err)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		drainAndCloseBody(resp.Body)
+		return nil, false, since, nil
+	}
+	defer closeResponseBody(resp.Body)
+
+	if header := resp.Header.Get("Last-Modified"); header != "" {
+		lastModified, _ = http.ParseTime(header)
+	}
+
+	reader, release, err := r.decodeBody(resp)
+	if err != nil {
+		return nil, false, time.Time{}, err
+	}
+	defer release()
+
+	body, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, false, time.Time{}, fmt.Errorf("error reading response from %s: %w", url, err)
+	}
+	return body, true, lastModified, nil
+}
@@ -0,0 +1,40 @@
+package requests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetIfModifiedSince(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		w.Write([]byte("<rss></rss>"))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(3, 0))
+
+	body, modified, got, err := r.GetIfModifiedSince(context.Background(), server.URL, time.Time{})
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	if !modified || string(body) != "<rss></rss>" || !got.Equal(lastModified) {
+		t.Fatalf("first fetch = (%q, %v, %v), want content, modified, %v", body, modified, got, lastModified)
+	}
+
+	body, modified, got, err = r.GetIfModifiedSince(context.Background(), server.URL, got)
+	if err != nil {
+		t.Fatalf("conditional fetch: %v", err)
+	}
+	if modified || body != nil || !got.Equal(lastModified) {
+		t.Fatalf("conditional fetch = (%q, %v, %v), want not modified", body, modified, got)
+	}
+}
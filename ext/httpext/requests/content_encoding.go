@@ -0,0 +1,86 @@
+package requests
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DefaultAcceptEncoding is the Accept-Encoding sent when neither WithAcceptEncoding nor the configured headers set one.
+const DefaultAcceptEncoding = "gzip"
+
+// WithAcceptEncoding sets the Accept-Encoding header of every request to the given content codings, most preferred
+// first, overriding any Accept-Encoding in the configured headers. Responses are decompressed by the client itself, so
+// only "gzip", "deflate" and "identity" should be offered; use WithAcceptEncoding("identity") to ask for uncompressed
+// responses.
+func WithAcceptEncoding(encodings ...string) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.acceptEncoding = strings.Join(encodings, ", ")
+	}
+}
+
+// disableTransportCompression turns off net/http's transparent gzip decompression on the client's transport, if it
+// owns one. Every request also carries an explicit Accept-Encoding (see requestHeaders), which keeps any transport,
+// including the shared http.DefaultTransport, from decompressing on its own. Either way responses reach decodeBody
+// with their Content-Encoding intact and are decompressed exactly once.
+func (r *RetryRequest) disableTransportCompression() {
+	if t, ok := r.client.Transport.(*http.Transport); ok {
+		t.DisableCompression = true
+	}
+}
+
+// decompressBody wraps the response body according to its Content-Encoding. The returned release func closes the
+// decompressing reader, if one was created.
+func decompressBody(resp *http.Response) (io.Reader, func(), error) {
+	release := func() {}
+	// A transport left to add its own Accept-Encoding has already decompressed the body and dropped the header
+	if resp.Uncompressed {
+		return resp.Body, release, nil
+	}
+
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return resp.Body, release, nil
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			slog.Error("Failed to create gzip reader", "err", err)
+			return nil, release, err
+		}
+		return gzipReader, closeDecompressor(gzipReader, "gzip"), nil
+	case "deflate":
+		// "deflate" is meant to be zlib wrapped, but some servers send a raw deflate stream instead
+		buffered := bufio.NewReader(resp.Body)
+		if header, err := buffered.Peek(2); err == nil && isZlibHeader(header) {
+			zlibReader, err := zlib.NewReader(buffered)
+			if err != nil {
+				slog.Error("Failed to create zlib reader", "err", err)
+				return nil, release, err
+			}
+			return zlibReader, closeDecompressor(zlibReader, "zlib"), nil
+		}
+		flateReader := flate.NewReader(buffered)
+		return flateReader, closeDecompressor(flateReader, "deflate"), nil
+	default:
+		return nil, release, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// isZlibHeader reports whether header starts a zlib stream: the deflate compression method and a valid check value
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+func closeDecompressor(reader io.Closer, name string) func() {
+	return func() {
+		if errLeak := reader.Close(); errLeak != nil {
+			slog.Error("Failed to close "+name+" reader, potential leak", "err", errLeak)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package requests
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAcceptEncodingHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Get("Accept-Encoding")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		options []RetryRequestOption
+		want    string
+	}{
+		{"default", nil, DefaultAcceptEncoding},
+		{"configured headers", []RetryRequestOption{WithHeaders(http.Header{"Accept-Encoding": {"gzip, deflate"}})}, "gzip, deflate"},
+		{"identity", []RetryRequestOption{WithAcceptEncoding("identity")}, "identity"},
+		{
+			"option overrides headers",
+			[]RetryRequestOption{WithHeaders(http.Header{"Accept-Encoding": {"gzip"}}), WithAcceptEncoding("deflate", "gzip")},
+			"deflate, gzip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRetryRequest(append(tt.options, WithAttemptsAndBackoff(1, 0))...)
+			if _, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Accept-Encoding = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGzipDecompressedOnce serves a gzip file with Content-Encoding: gzip, so the body is compressed twice over the
+// wire. Exactly one layer must be removed whatever Accept-Encoding the request was sent with.
+func TestGzipDecompressedOnce(t *testing.T) {
+	var file, wire bytes.Buffer
+	gz := gzip.NewWriter(&file)
+	_, _ = gz.Write([]byte("the file content"))
+	_ = gz.Close()
+	gz = gzip.NewWriter(&wire)
+	_, _ = gz.Write(file.Bytes())
+	_ = gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(wire.Bytes())
+	}))
+	defer server.Close()
+
+	tests := map[string][]RetryRequestOption{
+		"default":            nil,
+		"configured headers": {WithHeaders(http.Header{"Accept-Encoding": {"gzip, deflate"}})},
+		"option":             {WithAcceptEncoding("gzip")},
+		"own transport":      {WithDialTimeout(time.Second)},
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRetryRequest(append(options, WithAttemptsAndBackoff(1, 0))...)
+			got, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, file.Bytes()) {
+				t.Errorf("body = %q, want the gzip file %q", got, file.Bytes())
+			}
+		})
+	}
+}
+
+func TestDeflateResponse(t *testing.T) {
+	const want = "deflated content"
+
+	var zlibBody, rawBody bytes.Buffer
+	zw := zlib.NewWriter(&zlibBody)
+	_, _ = zw.Write([]byte(want))
+	_ = zw.Close()
+	fw, _ := flate.NewWriter(&rawBody, flate.DefaultCompression)
+	_, _ = fw.Write([]byte(want))
+	_ = fw.Close()
+
+	for name, body := range map[string][]byte{"zlib": zlibBody.Bytes(), "raw": rawBody.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Encoding", "deflate")
+				_, _ = w.Write(body)
+			}))
+			defer server.Close()
+
+			r := NewRetryRequest(WithAcceptEncoding("deflate"), WithAttemptsAndBackoff(1, 0))
+			got, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("body = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestUnsupportedContentEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = w.Write([]byte("not really brotli"))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(1, 0))
+	if _, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL); err == nil {
+		t.Error("expected an error for an unsupported content encoding")
+	}
+}
@@ -0,0 +1,58 @@
+package requests
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// WithDryRun configures the request to log each request it would make (method, URL, headers) instead of sending it,
+// answering with a synthetic 200 and an empty body. Rate limiting and backoff are skipped, so request construction
+// can be validated without network access.
+func WithDryRun() RetryRequestOption {
+	return WithDryRunResponse(http.StatusOK, "", nil)
+}
+
+// WithDryRunResponse is like WithDryRun but answers every request with the given canned status, content type and body.
+func WithDryRunResponse(statusCode int, contentType string, body []byte) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.dryRun = &dryRunTransport{statusCode: statusCode, contentType: contentType, body: body}
+	}
+}
+
+// dryRunTransport is an http.RoundTripper that logs requests and returns a canned response without any network I/O.
+type dryRunTransport struct {
+	statusCode  int
+	contentType string
+	body        []byte
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		closeResponseBody(req.Body)
+	}
+
+	slog.Info("Dry run, not sending request",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"header", redactHeader(req.Header))
+
+	header := make(http.Header)
+	if t.contentType != "" {
+		header.Set("Content-Type", t.contentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", t.statusCode, http.StatusText(t.statusCode)),
+		StatusCode:    t.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(t.body)),
+		ContentLength: int64(len(t.body)),
+		Request:       req,
+	}, nil
+}
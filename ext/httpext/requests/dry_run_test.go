@@ -0,0 +1,55 @@
+package requests
+
+import (
+	"context"
+	"golang.org/x/time/rate"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDryRun(t *testing.T) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	// An hour of backoff and rate limiting would make the test time out if they were not skipped
+	r := NewRetryRequest(
+		WithAttemptsAndBackoff(3, time.Hour),
+		WithRateLimiting(rate.Every(time.Hour), 1),
+		WithDryRunResponse(http.StatusOK, "application/json", []byte(`{"dry": true}`)),
+	)
+	for i := 0; i < 2; i++ {
+		body, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != `{"dry": true}` {
+			t.Errorf("GET body = %q, want the canned body", body)
+		}
+	}
+	body, err := r.PostContentsAsBytesWithContext(context.Background(), server.URL, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"dry": true}` {
+		t.Errorf("POST body = %q, want the canned body", body)
+	}
+
+	failing := NewRetryRequest(
+		WithAttemptsAndBackoff(3, time.Hour),
+		WithDryRunResponse(http.StatusServiceUnavailable, "", nil),
+	)
+	if _, err := failing.GetContentsAsBytesWithContext(context.Background(), server.URL); err == nil {
+		t.Error("GET answered with a canned 503 succeeded")
+	}
+
+	if got := hits.Load(); got != 0 {
+		t.Errorf("server hit %d times in dry run, want 0", got)
+	}
+}
@@ -0,0 +1,51 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"vmuser/ext/app"
+)
+
+// ErrNoFallbackURLs is returned by GetContentsWithFallback when it is called without any URLs to try.
+var ErrNoFallbackURLs = errors.New("no urls provided to fetch with fallback")
+
+// WithFallbackOn404 configures GetContentsWithFallback to move on to the next mirror when a mirror answers 404.
+// Without it a 404 is treated as authoritative and returned immediately. It only has an effect together with
+// WithNoRetry404, since otherwise a 404 is retried like any other failure and then falls through to the next mirror.
+func WithFallbackOn404() RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.fallbackOn404 = true
+	}
+}
+
+// GetContentsWithFallback tries each URL in order, through the full retry machinery, and returns the contents of the
+// first one that succeeds together with that URL. It only fails if every mirror fails, in which case the returned
+// error wraps an *app.MultiError holding the error from each mirror, keyed by its URL.
+func (r *RetryRequest) GetContentsWithFallback(ctx context.Context, urls []string) ([]byte, string, error) {
+	if len(urls) == 0 {
+		return nil, "", ErrNoFallbackURLs
+	}
+
+	var errs app.MultiError
+	for _, url := range urls {
+		bodyBytes, err := r.fetchContentsAsBytes(ctx, url)
+		if err == nil {
+			return bodyBytes, url, nil
+		}
+
+		errs.Add(url, err)
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, "", fmt.Errorf("%w after %d mirrors: %w", ctxErr, errs.Len(), &errs)
+		}
+		if Is404NoRetryError(err) && !r.fallbackOn404 {
+			return nil, "", err
+		}
+
+		slog.Info("Mirror failed, trying next", "url", url, "error", err)
+	}
+
+	return nil, "", fmt.Errorf("all %d mirrors failed: %w", len(urls), &errs)
+}
@@ -0,0 +1,58 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"vmuser/ext/app"
+)
+
+func TestGetContentsWithFallback(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("mirror content"))
+	}))
+	defer working.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(2, 0))
+	body, url, err := r.GetContentsWithFallback(context.Background(), []string{failing.URL, working.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "mirror content" || url != working.URL {
+		t.Errorf("got (%q, %s), want the content of the second mirror", body, url)
+	}
+
+	// When every mirror fails, each one's error is reported under its URL
+	_, _, err = r.GetContentsWithFallback(context.Background(), []string{failing.URL, missing.URL})
+	var errs *app.MultiError
+	if !errors.As(err, &errs) || errs.Len() != 2 {
+		t.Fatalf("err = %v, want a MultiError with an error per mirror", err)
+	}
+	if errs.Errors()[0].Key != failing.URL || errs.Errors()[1].Key != missing.URL {
+		t.Errorf("errors keyed by %q and %q, want the mirror URLs", errs.Errors()[0].Key, errs.Errors()[1].Key)
+	}
+
+	// A 404 that is not retried is authoritative unless WithFallbackOn404 is set
+	noRetry404 := NewRetryRequest(WithAttemptsAndBackoff(2, 0), WithNoRetry404())
+	_, _, err = noRetry404.GetContentsWithFallback(context.Background(), []string{missing.URL, working.URL})
+	if !Is404NoRetryError(err) {
+		t.Fatalf("err = %v, want the 404 of the first mirror", err)
+	}
+	onward := NewRetryRequest(WithAttemptsAndBackoff(2, 0), WithNoRetry404(), WithFallbackOn404())
+	if _, url, err := onward.GetContentsWithFallback(context.Background(), []string{missing.URL, working.URL}); err != nil ||
+		url != working.URL {
+		t.Errorf("with WithFallbackOn404 got (%s, %v), want the second mirror", url, err)
+	}
+
+	if _, _, err := r.GetContentsWithFallback(context.Background(), nil); !errors.Is(err, ErrNoFallbackURLs) {
+		t.Errorf("err = %v, want ErrNoFallbackURLs", err)
+	}
+}
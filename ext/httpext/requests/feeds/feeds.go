@@ -0,0 +1,274 @@
+// Package feeds fetches and parses RSS 2.0 and Atom feeds into a single Feed type, built on requests.RetryRequest
+// with headers.RSSFeedHeaders.
+package feeds
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html/charset"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"vmuser/ext/httpext/headers"
+	"vmuser/ext/httpext/requests"
+)
+
+// ErrUnknownFormat is returned by ParseFeed when the document is neither an RSS 2.0 nor an Atom feed.
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Feed is an RSS or Atom feed.
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Updated     time.Time // zero if the feed does not say
+	Items       []Item
+}
+
+// Item is a single entry of a feed.
+type Item struct {
+	Title       string
+	Link        string
+	Description string
+	// GUID identifies the item across fetches: the RSS guid or Atom id, falling back to the link
+	GUID      string
+	Published time.Time // zero if the item is undated
+}
+
+// shared RetryRequest of FetchFeed and its initialization syncing
+var (
+	defaultRequest     *requests.RetryRequest
+	onceDefaultRequest sync.Once
+)
+
+// FetchFeed fetches and parses the feed at url using a RetryRequest with the RSS feed headers. The RetryRequest is
+// created on first use and shared by every call for the lifetime of the process, so connections are reused.
+func FetchFeed(ctx context.Context, url string) (*Feed, error) {
+	onceDefaultRequest.Do(func() {
+		defaultRequest = requests.NewRetryRequest(requests.WithHeaders(headers.RSSFeedHeaders()))
+	})
+	return FetchFeedWith(ctx, defaultRequest, url)
+}
+
+// FetchFeedWith fetches and parses the feed at url using r, for callers that need their own retry, rate limiting or
+// transport settings.
+func FetchFeedWith(ctx context.Context, r *requests.RetryRequest, url string) (*Feed, error) {
+	body, err := r.GetContentsAsBytesWithContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching feed %s: %w", url, err)
+	}
+
+	feed, err := ParseFeed(body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing feed %s: %w", url, err)
+	}
+	return feed, nil
+}
+
+// ParseFeed parses an RSS 2.0 or Atom document. Documents declaring a non UTF-8 encoding are decoded accordingly,
+// unless the content is already valid UTF-8, as it is once the fetch path has transcoded it using the response's
+// Content-Type.
+func ParseFeed(data []byte) (*Feed, error) {
+	decoder := newDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, ErrUnknownFormat
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rss":
+			var doc rssDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, err
+			}
+			return doc.feed(), nil
+		case "feed":
+			var doc atomFeed
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, err
+			}
+			return doc.feed(), nil
+		default:
+			return nil, fmt.Errorf("%w: root element <%s>", ErrUnknownFormat, start.Name.Local)
+		}
+	}
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	alreadyUTF8 := utf8.Valid(data)
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		// The declaration still names the original charset after the body has been transcoded
+		if alreadyUTF8 {
+			return input, nil
+		}
+		return charset.NewReaderLabel(label, input)
+	}
+	// Feeds in the wild frequently contain HTML entities and unescaped ampersands
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title         string    `xml:"title"`
+		Links         []rssLink `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		PubDate       string    `xml:"pubDate"`
+		Items         []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// rssLink matches both the RSS <link> and the <atom:link rel="self"> that many RSS feeds also carry
+type rssLink struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// rssLinkValue returns the RSS link, skipping namespaced links such as atom:link
+func rssLinkValue(links []rssLink) string {
+	for _, link := range links {
+		if link.XMLName.Space == "" && strings.TrimSpace(link.Value) != "" {
+			return strings.TrimSpace(link.Value)
+		}
+	}
+	return ""
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Links       []rssLink `xml:"link"`
+	Description string    `xml:"description"`
+	GUID        string    `xml:"guid"`
+	PubDate     string    `xml:"pubDate"`
+	// Dublin Core date, used by some feeds instead of pubDate
+	Date string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+func (doc *rssDocument) feed() *Feed {
+	channel := doc.Channel
+	feed := &Feed{
+		Title:       strings.TrimSpace(channel.Title),
+		Link:        rssLinkValue(channel.Links),
+		Description: strings.TrimSpace(channel.Description),
+		Updated:     parseTime(firstNonEmpty(channel.LastBuildDate, channel.PubDate)),
+	}
+	for _, item := range channel.Items {
+		link := rssLinkValue(item.Links)
+		feed.Items = append(feed.Items, Item{
+			Title:       strings.TrimSpace(item.Title),
+			Link:        link,
+			Description: strings.TrimSpace(item.Description),
+			GUID:        firstNonEmpty(strings.TrimSpace(item.GUID), link),
+			Published:   parseTime(firstNonEmpty(item.PubDate, item.Date)),
+		})
+	}
+	return feed
+}
+
+type atomFeed struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Links     []atomLink `xml:"link"`
+}
+
+func (doc *atomFeed) feed() *Feed {
+	feed := &Feed{
+		Title:       strings.TrimSpace(doc.Title),
+		Link:        alternateLink(doc.Links),
+		Description: strings.TrimSpace(doc.Subtitle),
+		Updated:     parseTime(doc.Updated),
+	}
+	for _, entry := range doc.Entries {
+		link := alternateLink(entry.Links)
+		feed.Items = append(feed.Items, Item{
+			Title:       strings.TrimSpace(entry.Title),
+			Link:        link,
+			Description: strings.TrimSpace(firstNonEmpty(entry.Summary, entry.Content)),
+			GUID:        firstNonEmpty(strings.TrimSpace(entry.ID), link),
+			Published:   parseTime(firstNonEmpty(entry.Published, entry.Updated)),
+		})
+	}
+	return feed
+}
+
+// alternateLink returns the rel="alternate" link, which is also the default when rel is omitted
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+// timeLayouts are the date formats seen in feeds: RFC 822 variants for RSS and RFC 3339 for Atom
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseTime parses a feed date, returning the zero time for missing or unrecognized dates
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
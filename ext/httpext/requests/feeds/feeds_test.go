@@ -0,0 +1,127 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vmuser/ext/httpext/requests"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+  <title>Filings</title>
+  <atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"/>
+  <link>https://example.com/</link>
+  <description>Latest filings</description>
+  <lastBuildDate>Wed, 01 May 2024 12:00:00 GMT</lastBuildDate>
+  <item>
+    <title>10-K &amp; exhibits</title>
+    <link>https://example.com/10-k</link>
+    <guid isPermaLink="false">filing-1</guid>
+    <pubDate>Wed, 1 May 2024 10:30:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>No guid</title>
+    <link>https://example.com/8-k</link>
+  </item>
+</channel>
+</rss>`
+
+const atomFeedXML = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom filings</title>
+  <link href="https://example.com/atom" rel="self"/>
+  <link href="https://example.com/"/>
+  <updated>2024-05-01T12:00:00Z</updated>
+  <entry>
+    <title>Entry one</title>
+    <link rel="alternate" href="https://example.com/one"/>
+    <id>urn:uuid:1</id>
+    <published>2024-05-01T10:30:00Z</published>
+    <summary>First</summary>
+  </entry>
+</feed>`
+
+func TestParseFeedRSS(t *testing.T) {
+	feed, err := ParseFeed([]byte(rssFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+	if feed.Title != "Filings" || feed.Link != "https://example.com/" {
+		t.Errorf("feed = %q %q", feed.Title, feed.Link)
+	}
+	if !feed.Updated.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Updated = %v", feed.Updated)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(feed.Items))
+	}
+	first := feed.Items[0]
+	if first.Title != "10-K & exhibits" || first.GUID != "filing-1" || first.Link != "https://example.com/10-k" {
+		t.Errorf("first item = %+v", first)
+	}
+	if !first.Published.Equal(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Published = %v", first.Published)
+	}
+	if feed.Items[1].GUID != "https://example.com/8-k" {
+		t.Errorf("GUID fallback = %q, want the link", feed.Items[1].GUID)
+	}
+}
+
+func TestParseFeedAtom(t *testing.T) {
+	feed, err := ParseFeed([]byte(atomFeedXML))
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+	if feed.Title != "Atom filings" || feed.Link != "https://example.com/" {
+		t.Errorf("feed = %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Items))
+	}
+	entry := feed.Items[0]
+	if entry.GUID != "urn:uuid:1" || entry.Link != "https://example.com/one" || entry.Description != "First" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if !entry.Published.Equal(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Published = %v", entry.Published)
+	}
+}
+
+func TestParseFeedLatin1(t *testing.T) {
+	// "Café" in ISO-8859-1, as served without a charset in the Content-Type
+	doc := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><title>Caf\xe9</title></channel></rss>")
+	feed, err := ParseFeed(doc)
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+	if feed.Title != "Café" {
+		t.Errorf("Title = %q, want Café", feed.Title)
+	}
+}
+
+func TestParseFeedUnknownFormat(t *testing.T) {
+	if _, err := ParseFeed([]byte(`<html><body>not a feed</body></html>`)); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("err = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestFetchFeedWithTranscodedCharset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=ISO-8859-1")
+		w.Write([]byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><title>Caf\xe9</title></channel></rss>"))
+	}))
+	defer server.Close()
+
+	feed, err := FetchFeedWith(context.Background(), requests.NewRetryRequest(requests.WithAttemptsAndBackoff(1, 0)), server.URL)
+	if err != nil {
+		t.Fatalf("FetchFeedWith: %v", err)
+	}
+	if feed.Title != "Café" {
+		t.Errorf("Title = %q, want Café", feed.Title)
+	}
+}
@@ -11,9 +11,9 @@ type Fetcher interface {
 }
 
 type FetcherReader interface {
-	// GetContentsAsReader returns a reader for the contents of the URL.
+	// GetContentsAsReader returns a reader for the contents of the URL. The caller must Close it.
 	// Note: In the future we will want this to also return the size of the content
-	GetContentsAsReader(url string) (io.Reader, error)
+	GetContentsAsReader(url string) (io.ReadCloser, error)
 }
 
 type FetcherWithContext interface {
@@ -26,5 +26,5 @@ type FetcherWithContextFromRedirect interface {
 
 type FetcherBytesAndReader interface {
 	GetContentsAsBytes(url string) ([]byte, error)
-	GetContentsAsReader(url string) (io.Reader, error)
+	GetContentsAsReader(url string) (io.ReadCloser, error)
 }
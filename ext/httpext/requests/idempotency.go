@@ -0,0 +1,14 @@
+package requests
+
+// IdempotencyKeyHeader is the header WithIdempotencyKey sends the key of a logical POST in.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sends an Idempotency-Key header with every POST. keyFunc is called once per logical POST and
+// the key is resent unchanged with each retry, together with the same body, so a server that supports idempotency keys
+// can recognize a retry of a request it already processed, e.g. one whose response was lost, instead of acting on it
+// twice.
+func WithIdempotencyKey(keyFunc func() string) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.idempotencyKeyFunc = keyFunc
+	}
+}
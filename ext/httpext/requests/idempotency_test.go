@@ -0,0 +1,47 @@
+package requests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIdempotencyKeyAndBodyResentOnRetry(t *testing.T) {
+	var keys, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		bodies = append(bodies, string(body))
+		if len(keys)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("created"))
+	}))
+	defer server.Close()
+
+	var calls atomic.Int64
+	r := NewRetryRequest(
+		WithAttemptsAndBackoff(3, 0),
+		WithIdempotencyKey(func() string { return fmt.Sprintf("key-%d", calls.Add(1)) }),
+	)
+	for i := 0; i < 2; i++ {
+		_, err := r.PostContentsAsBytesWithContext(context.Background(), server.URL, strings.NewReader(`{"name":"x"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := []string{"key-1", "key-1", "key-1", "key-2", "key-2", "key-2"}; !slices.Equal(keys, want) {
+		t.Errorf("idempotency keys = %v, want %v", keys, want)
+	}
+	if want := slices.Repeat([]string{`{"name":"x"}`}, 6); !slices.Equal(bodies, want) {
+		t.Errorf("bodies = %q, want the same body on every attempt", bodies)
+	}
+}
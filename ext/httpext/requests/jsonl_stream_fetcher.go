@@ -14,11 +14,12 @@ import (
 
 // JSONLStreamFetcher represents a fetcher for JSONL streams.
 type JSONLStreamFetcher struct {
-	PollInterval time.Duration
-	URL          string
-	StartMessage *StartMessage
-	EndMessage   *EndMessage
-	HttpClient   *http.Client
+	PollInterval   time.Duration
+	RequestTimeout time.Duration
+	URL            string
+	StartMessage   *StartMessage
+	EndMessage     *EndMessage
+	HttpClient     *http.Client
 }
 
 // JSONLStreamFetcherOption is a function that configures a JSONLStreamFetcher.
@@ -38,12 +39,21 @@ func WithHttpClient(client *http.Client) JSONLStreamFetcherOption {
 	}
 }
 
+// WithPollRequestTimeout returns a JSONLStreamFetcherOption that bounds how long a single poll request, including
+// reading its body, may take before it is aborted.
+func WithPollRequestTimeout(timeout time.Duration) JSONLStreamFetcherOption {
+	return func(f *JSONLStreamFetcher) {
+		f.RequestTimeout = timeout
+	}
+}
+
 // NewJSONLStreamFetcher creates a new JSONLStreamFetcher with the given URL and options.
 func NewJSONLStreamFetcher(url string, options ...JSONLStreamFetcherOption) *JSONLStreamFetcher {
 	fetcher := &JSONLStreamFetcher{
-		PollInterval: time.Second,
-		URL:          url,
-		HttpClient:   &http.Client{},
+		PollInterval:   time.Second,
+		RequestTimeout: DefaultRequestTimeout,
+		URL:            url,
+		HttpClient:     &http.Client{},
 	}
 
 	for _, option := range options {
@@ -63,88 +73,102 @@ func (f *JSONLStreamFetcher) FetchJSONLStream(ctx context.Context) <-chan string
 		lastBytePosition := int64(0)
 
 		for {
-			req, err := http.NewRequestWithContext(ctx, "GET", f.URL, nil)
-			if err != nil {
-				slog.Error("Error creating request", "err", err)
+			var done bool
+			lastBytePosition, done = f.poll(ctx, lastBytePosition, resultChan)
+			if done {
 				return
 			}
 
-			if lastBytePosition > 0 {
-				req.Header.Set("Range", fmt.Sprintf("bytes=%d-", lastBytePosition))
-			}
-
-			resp, err := f.HttpClient.Do(req)
-			if err != nil {
-				slog.Error("Error fetching JSONL", "err", err, "url", f.URL)
+			select {
+			case <-time.After(f.PollInterval):
+			case <-ctx.Done():
+				slog.Info("Context canceled, stopping JSONL stream fetcher")
 				return
 			}
-			defer func(Body io.ReadCloser) {
-				err := Body.Close()
-				if err != nil {
-					slog.Error("Error closing response body", "err", err)
-				}
-			}(resp.Body)
-
-			if resp.StatusCode == http.StatusPartialContent {
-				scanner := bufio.NewScanner(resp.Body)
-				for scanner.Scan() {
-					line := scanner.Text()
-					resultChan <- line
-
-					if strings.HasPrefix(line, `{"type":"start"`) {
-						var startMsg StartMessage
-						if err := json.Unmarshal([]byte(line), &startMsg); err == nil {
-							slog.Info("Received start of stream", "message", startMsg)
-							f.StartMessage = &startMsg
-						} else {
-							slog.Error("Error parsing start message", "err", err)
-						}
-					}
+		}
+	}()
 
-					if strings.HasPrefix(line, `{"type":"end"`) {
-						var endMsg EndMessage
-						if err := json.Unmarshal([]byte(line), &endMsg); err == nil {
-							if endMsg.Type == "end" {
-								slog.Info("Received end of stream", "message", endMsg)
-								f.EndMessage = &endMsg
-								return
-							}
-						} else {
-							slog.Error("Error parsing end message", "err", err)
-						}
-					}
-				}
+	return resultChan
+}
 
-				if err := scanner.Err(); err != nil {
-					slog.Error("Error scanning JSONL", "err", err)
-					return
-				}
+// poll makes a single request for the stream from lastBytePosition onwards, sending each line to resultChan. The
+// request is bounded by RequestTimeout, derived from ctx. It returns the position to resume from and whether the
+// stream is finished.
+func (f *JSONLStreamFetcher) poll(ctx context.Context, lastBytePosition int64, resultChan chan<- string) (int64, bool) {
+	ctx, cancel := context.WithTimeout(ctx, f.RequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", f.URL, nil)
+	if err != nil {
+		slog.Error("Error creating request", "err", err)
+		return lastBytePosition, true
+	}
 
-				lastBytePosition = resp.ContentLength
-			} else if resp.StatusCode == http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					slog.Error("Error reading response body", "err", err)
-					return
-				}
+	if lastBytePosition > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", lastBytePosition))
+	}
 
-				resultChan <- string(body)
-				return
-			} else {
-				slog.Error("Unexpected status code", "status_code", resp.StatusCode)
-				return
+	resp, err := f.HttpClient.Do(req)
+	if err != nil {
+		slog.Error("Error fetching JSONL", "err", err, "url", f.URL)
+		return lastBytePosition, true
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			slog.Error("Error closing response body", "err", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode == http.StatusPartialContent {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			resultChan <- line
+
+			if strings.HasPrefix(line, `{"type":"start"`) {
+				var startMsg StartMessage
+				if err := json.Unmarshal([]byte(line), &startMsg); err == nil {
+					slog.Info("Received start of stream", "message", startMsg)
+					f.StartMessage = &startMsg
+				} else {
+					slog.Error("Error parsing start message", "err", err)
+				}
 			}
 
-			select {
-			case <-time.After(f.PollInterval):
-			case <-ctx.Done():
-				slog.Info("Context canceled, stopping JSONL stream fetcher")
-				return
+			if strings.HasPrefix(line, `{"type":"end"`) {
+				var endMsg EndMessage
+				if err := json.Unmarshal([]byte(line), &endMsg); err == nil {
+					if endMsg.Type == "end" {
+						slog.Info("Received end of stream", "message", endMsg)
+						f.EndMessage = &endMsg
+						return lastBytePosition, true
+					}
+				} else {
+					slog.Error("Error parsing end message", "err", err)
+				}
 			}
 		}
-	}()
 
-	return resultChan
+		if err := scanner.Err(); err != nil {
+			slog.Error("Error scanning JSONL", "err", err)
+			return lastBytePosition, true
+		}
+
+		return resp.ContentLength, false
+	} else if resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			slog.Error("Error reading response body", "err", err)
+			return lastBytePosition, true
+		}
+
+		resultChan <- string(body)
+		return lastBytePosition, true
+	}
+
+	slog.Error("Unexpected status code", "status_code", resp.StatusCode)
+	return lastBytePosition, true
 }
 
 // EndMessage represents the structure of the end message in the JSONL stream.
@@ -0,0 +1,59 @@
+package requests
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// GetLines streams the decoded response body from url line by line, so very large text files can be processed with
+// bounded memory. Lines are sent on the first channel; at most one error is sent on the second. Both channels are
+// closed once the body has been read, an error occurs, or ctx is cancelled, at which point the response body is
+// closed and the request context released.
+//
+// Lines longer than the configured maximum (see WithMaxLineSize) fail the stream with bufio.ErrTooLong.
+func (r *RetryRequest) GetLines(ctx context.Context, url string) (<-chan string, <-chan error) {
+	lines := make(chan string)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(lines)
+		defer close(errChan)
+
+		resp, cancel, err := r.GetResponse(ctx, url)
+		if cancel != nil {
+			defer cancel()
+		}
+		if err != nil {
+			errChan <- fmt.Errorf("failed to get a response for the URL %s: %w", url, err)
+			return
+		}
+		defer closeResponseBody(resp.Body)
+
+		reader, release, err := r.decodeBody(resp)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		defer release()
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, min(r.maxLineSize, bufio.MaxScanTokenSize)), r.maxLineSize)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			slog.Error("Error scanning response lines", "err", err, "url", url)
+			errChan <- fmt.Errorf("error scanning response from %s: %w", url, err)
+		}
+	}()
+
+	return lines, errChan
+}
@@ -2,32 +2,50 @@ package requests
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
-	"strings"
+	"syscall"
 	"time"
+	"vmuser/ext/httpext"
 )
 
 // IsPossibleNetworkOrDNSIssueErr analyzes the error and logs a specific warning if it detects a network or DNS resolution issue.
+// It delegates to httpext.IsDialError so the same errors.As based classification is used everywhere, and additionally
+// treats a refused or reset connection anywhere in the error chain as a network issue.
 func IsPossibleNetworkOrDNSIssueErr(err error, url string) bool {
 	if err == nil {
 		return false
 	}
-	errMsg := err.Error()
-	if strings.Contains(errMsg, "dial tcp") && strings.Contains(errMsg, "i/o timeout") {
+	if httpext.IsDialError(err) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
 		slog.Warn("Network or DNS resolution issue detected", "error", err, "url", url)
 		return true
 	}
 	return false
 }
 
+// DefaultNetworkProbeURLs are the well-known sites probed in parallel to decide whether the network as a whole is down.
+var DefaultNetworkProbeURLs = []string{
+	"https://www.google.com",
+	"https://wikipedia.org",
+	"https://twitter.com/home",
+	"https://www.facebook.com",
+}
+
+// DefaultNetworkProbeTimeout bounds how long the network availability probes may take.
+const DefaultNetworkProbeTimeout = 10 * time.Second
+
 // IsNetworkUnavailable tries to determine if a network or DNS issue might be indicating a broader internet outage.
 func IsNetworkUnavailable(err error, url string) bool {
+	return isNetworkUnavailable(context.Background(), err, url, DefaultNetworkProbeURLs, DefaultNetworkProbeTimeout)
+}
+
+func isNetworkUnavailable(ctx context.Context, err error, url string, probeURLs []string, probeTimeout time.Duration) bool {
 	if !IsPossibleNetworkOrDNSIssueErr(err, url) {
 		return false
 	}
-	return isNetworkAvailableCheck()
+	return !isNetworkAvailableCheck(ctx, probeURLs, probeTimeout)
 }
 
 // closeResponseBody safely closes the HTTP response body.
@@ -37,23 +55,28 @@ func closeResponseBody(body io.ReadCloser) {
 	}
 }
 
-func isNetworkAvailableCheck() bool {
-	urls := []string{
-		"https://www.google.com",
-		"https://wikipedia.org",
-		"https://twitter.com/home",
-		"https://www.facebook.com",
+// isNetworkAvailableCheck probes every URL in parallel and reports true as soon as any of them responds. The remaining
+// in-flight probes are cancelled once the first one succeeds, so no goroutines or sockets outlive the call for long.
+func isNetworkAvailableCheck(ctx context.Context, urls []string, timeout time.Duration) bool {
+	if len(urls) == 0 {
+		return false
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	responses := make(chan bool, len(urls))
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := &http.Client{}
 
 	for _, url := range urls {
 		go func(url string) {
-			req, _ := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+			req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+			if err != nil {
+				slog.Warn("Failed to create network probe request", "error", err, "url", url)
+				responses <- false
+				return
+			}
 			resp, err := client.Do(req)
 			if err == nil {
 				closeResponseBody(resp.Body)
@@ -64,7 +87,7 @@ func isNetworkAvailableCheck() bool {
 
 	for range urls {
 		if <-responses {
-			return true // If any request succeeds, return true immediately
+			return true // If any request succeeds, return true immediately; the deferred cancel stops the rest
 		}
 	}
 	return false // If all requests failed, return false
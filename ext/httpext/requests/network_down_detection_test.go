@@ -0,0 +1,67 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsPossibleNetworkOrDNSIssueErr(t *testing.T) {
+	const target = "https://www.sec.gov/"
+
+	dialRefused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("something else went wrong"), false},
+		{"dial connection refused", dialRefused, true},
+		{"connection refused wrapped by http client", &url.Error{Op: "Get", URL: target, Err: dialRefused}, true},
+		{"bare connection refused", fmt.Errorf("request failed: %w", syscall.ECONNREFUSED), true},
+		{"bare connection reset", fmt.Errorf("request failed: %w", syscall.ECONNRESET), true},
+		{"dial tcp i/o timeout string", errors.New("dial tcp 1.2.3.4:443: i/o timeout"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPossibleNetworkOrDNSIssueErr(tt.err, target); got != tt.want {
+				t.Fatalf("IsPossibleNetworkOrDNSIssueErr(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelAbortsNetworkRecoveryWait(t *testing.T) {
+	// A closed server refuses connections, so both the request and the probe fail as if the network were down
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down := server.URL
+	server.Close()
+
+	r := NewRetryRequest(
+		WithAttemptsAndBackoff(1, 0),
+		WithNetworkRetryPolicy(time.Hour, 2*time.Hour),
+		WithNetworkProbe([]string{down}, time.Second),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := r.GetContentsAsBytesWithContext(ctx, down)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v, want promptly after the cancel", elapsed)
+	}
+}
@@ -0,0 +1,62 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Paginate walks a paginated JSON API starting at start, fetching each page with r (so every page gets r's retries,
+// rate limiting and headers) and sending its raw JSON on the first channel. After each page is sent, next extracts the
+// URL of the following page from it; pagination stops when next reports done or returns an empty URL. At most one
+// error is sent on the second channel. Both channels are closed once pagination finishes, a page fails to fetch or is
+// not valid JSON, or ctx is cancelled.
+//
+// Example, for an API returning {"items": [...], "next": "https://..."}:
+//
+//	pages, errs := requests.Paginate(ctx, r, "https://api.example.com/items", func(page json.RawMessage) (string, bool) {
+//	    var p struct{ Next string `json:"next"` }
+//	    if err := json.Unmarshal(page, &p); err != nil {
+//	        return "", true
+//	    }
+//	    return p.Next, p.Next == ""
+//	})
+//	for page := range pages {
+//	    // decode and process page
+//	}
+//	if err := <-errs; err != nil {
+//	    return err
+//	}
+func Paginate(ctx context.Context, r *RetryRequest, start string, next func(page json.RawMessage) (nextURL string, done bool)) (<-chan json.RawMessage, <-chan error) {
+	pages := make(chan json.RawMessage)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(pages)
+		defer close(errChan)
+
+		url := start
+		for {
+			var page json.RawMessage
+			if err := r.GetJSON(ctx, url, &page); err != nil {
+				errChan <- fmt.Errorf("error fetching page %s: %w", url, err)
+				return
+			}
+
+			select {
+			case pages <- page:
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+
+			nextURL, done := next(page)
+			if done || nextURL == "" {
+				return
+			}
+			url = nextURL
+		}
+	}()
+
+	return pages, errChan
+}
@@ -0,0 +1,56 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPaginateFollowsNext(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		next := ""
+		switch page {
+		case "":
+			next = server.URL + "/?page=2"
+		case "2":
+			next = server.URL + "/?page=3"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"page": %q, "next": %q}`, page, next)
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(1, 0))
+	pages, errs := Paginate(context.Background(), r, server.URL, func(page json.RawMessage) (string, bool) {
+		var p struct {
+			Next string `json:"next"`
+		}
+		if err := json.Unmarshal(page, &p); err != nil {
+			return "", true
+		}
+		return p.Next, p.Next == ""
+	})
+
+	var got []string
+	for page := range pages {
+		var p struct {
+			Page string `json:"page"`
+		}
+		if err := json.Unmarshal(page, &p); err != nil {
+			t.Fatalf("failed to decode page: %v", err)
+		}
+		got = append(got, p.Page)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Paginate returned error: %v", err)
+	}
+
+	if len(got) != 3 || got[0] != "" || got[1] != "2" || got[2] != "3" {
+		t.Fatalf("got pages %q, want [\"\" \"2\" \"3\"]", got)
+	}
+}
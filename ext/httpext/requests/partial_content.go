@@ -0,0 +1,115 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrUnexpectedPartialContent is returned when WithRejectUnexpectedPartial is configured and a request sent without a
+// Range header is answered with 206 Partial Content, whose body is only part of the resource.
+var ErrUnexpectedPartialContent = errors.New("unexpected 206 Partial Content for a request without a Range header")
+
+// ErrRangeMismatch is returned by GetRange when the server does not answer with the requested byte range, e.g. because
+// it ignored the Range header and sent the whole resource.
+var ErrRangeMismatch = errors.New("response does not match the requested range")
+
+// WithRejectUnexpectedPartial makes a 206 Partial Content response to a request that did not send a Range header an
+// error (ErrUnexpectedPartialContent) instead of a success, so a partial body is never mistaken for the whole resource.
+// Requests that do send a Range, such as GetRange or ones with a Range set through WithHeaders, are unaffected.
+func WithRejectUnexpectedPartial() RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.rejectPartial = true
+	}
+}
+
+// unexpectedPartial returns ErrUnexpectedPartialContent for a 206 response to a request that did not ask for a range
+func unexpectedPartial(resp *http.Response, url string) error {
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil
+	}
+	if resp.Request != nil && resp.Request.Header.Get("Range") != "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnexpectedPartialContent, url)
+}
+
+// GetRange sends a GET for bytes start through end (inclusive) of url, with the usual retries, and returns them. A
+// negative end requests everything from start on. The response must be a 206 Partial Content whose Content-Range
+// starts at start, ends at end (or at the last byte, if the resource is shorter) and matches the length of the body;
+// anything else, including a 200 with the full resource, fails with ErrRangeMismatch. The body is returned as sent,
+// without content or charset decoding, since the offsets refer to the resource's bytes.
+func (r *RetryRequest) GetRange(ctx context.Context, url string, start, end int64) ([]byte, error) {
+	if start < 0 || (end >= 0 && end < start) {
+		return nil, fmt.Errorf("invalid range %d-%d", start, end)
+	}
+
+	spec := fmt.Sprintf("bytes=%d-", start)
+	if end >= 0 {
+		spec += strconv.FormatInt(end, 10)
+	}
+	// Ranges of a compressed representation would not line up with the resource's bytes
+	opts := getOptions{header: http.Header{"Range": {spec}, "Accept-Encoding": {"identity"}}}
+
+	resp, cancel, err := r.withConcurrencySlot(ctx, func() (*http.Response, context.CancelFunc, error) {
+		return r.getResponse(ctx, url, opts)
+	})
+	if cancel != nil {
+		defer cancel()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a response for the URL %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		drainAndCloseBody(resp.Body)
+		return nil, fmt.Errorf("%w: %s answered %s to %s", ErrRangeMismatch, url, resp.Status, spec)
+	}
+	defer closeResponseBody(resp.Body)
+
+	first, last, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrRangeMismatch, url, err)
+	}
+	if first != start || (end >= 0 && last > end) {
+		return nil, fmt.Errorf("%w: %s sent bytes %d-%d for %s", ErrRangeMismatch, url, first, last, spec)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from %s: %w", url, err)
+	}
+	if int64(len(body)) != last-first+1 {
+		return nil, fmt.Errorf("%w: %s sent %d bytes for bytes %d-%d", ErrRangeMismatch, url, len(body), first, last)
+	}
+	return body, nil
+}
+
+// parseContentRange parses a Content-Range header of the form "bytes first-last/size", where size may be "*"
+func parseContentRange(value string) (first, last int64, err error) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	span, _, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	firstText, lastText, ok := strings.Cut(span, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	if first, err = strconv.ParseInt(firstText, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q: %w", value, err)
+	}
+	if last, err = strconv.ParseInt(lastText, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q: %w", value, err)
+	}
+	if last < first {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	return first, last, nil
+}
@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetRange(t *testing.T) {
+	content := []byte("0123456789")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ignores-range" {
+			w.Write(content)
+			return
+		}
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(3, 0), WithRejectUnexpectedPartial())
+
+	tests := []struct {
+		name       string
+		start, end int64
+		want       string
+	}{
+		{"closed", 2, 5, "2345"},
+		{"open ended", 7, -1, "789"},
+		{"past the end", 8, 20, "89"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.GetRange(context.Background(), server.URL, tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("GetRange(%d, %d) = %q, want %q", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := r.GetRange(context.Background(), server.URL+"/ignores-range", 2, 5); !errors.Is(err, ErrRangeMismatch) {
+		t.Errorf("err = %v, want ErrRangeMismatch", err)
+	}
+}
+
+func TestRejectUnexpectedPartial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-3/10")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("0123"))
+	}))
+	defer server.Close()
+
+	lenient := NewRetryRequest(WithAttemptsAndBackoff(3, 0))
+	if body, err := lenient.GetContentsAsBytesWithContext(context.Background(), server.URL); err != nil || string(body) != "0123" {
+		t.Errorf("without the option = (%q, %v), want the partial body", body, err)
+	}
+
+	strict := NewRetryRequest(WithAttemptsAndBackoff(3, 0), WithRejectUnexpectedPartial())
+	if _, err := strict.GetContentsAsBytesWithContext(context.Background(), server.URL); !errors.Is(err, ErrUnexpectedPartialContent) {
+		t.Errorf("err = %v, want ErrUnexpectedPartialContent", err)
+	}
+
+	ranged := NewRetryRequest(
+		WithAttemptsAndBackoff(3, 0),
+		WithRejectUnexpectedPartial(),
+		WithHeaders(http.Header{"Range": {"bytes=0-3"}}),
+	)
+	if _, err := ranged.GetContentsAsBytesWithContext(context.Background(), server.URL); err != nil {
+		t.Errorf("with an explicit Range: %v", err)
+	}
+}
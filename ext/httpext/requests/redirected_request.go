@@ -38,6 +38,11 @@ func NewRedirectedRequest(options ...RetryRequestOption) *RedirectedRequest {
 	return rr
 }
 
+// Close closes the idle connections of the underlying RetryRequest. See RetryRequest.Close.
+func (rr *RedirectedRequest) Close() {
+	rr.retryRequest.Close()
+}
+
 func (rr *RedirectedRequest) GetContentsAsBytesWithContextAndFinalURL(ctx context.Context, urlStr string) ([]byte, url.URL, error) {
 	return rr.getContentsAsBytesWithContextAndFinalURL(ctx, urlStr, true)
 }
@@ -67,7 +72,14 @@ func (rr *RedirectedRequest) getContentsAsBytesWithContextAndFinalURL(ctx contex
 		}
 	}(resp.Body)
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// GetResponse hands over the body as sent, so decompress and decode it before looking for a redirect in it
+	reader, release, err := rr.retryRequest.decodeBody(resp)
+	if err != nil {
+		return nil, url.URL{}, fmt.Errorf("failed to decode response body: %w", err)
+	}
+	defer release()
+
+	bodyBytes, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, url.URL{}, fmt.Errorf("failed to read response body: %w", err)
 	}
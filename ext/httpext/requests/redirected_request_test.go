@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// gzipHandler serves body gzip compressed, as servers do for clients that send Accept-Encoding: gzip
+func gzipHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(body))
+		gz.Close()
+	}
+}
+
+func TestRedirectedRequestGzip(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.Handle("/start", gzipHandler(`<script>location.replace("`+server.URL+`/final")</script>`))
+	mux.Handle("/final", gzipHandler("<p>final page</p>"))
+
+	rr := NewRedirectedRequest(WithAttemptsAndBackoff(1, 0))
+	defer rr.Close()
+
+	body, finalURL, err := rr.GetContentsAsBytesWithContextAndFinalURL(context.Background(), server.URL+"/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "<p>final page</p>" {
+		t.Errorf("body = %q, want the decompressed final page", body)
+	}
+	if finalURL.Path != "/final" {
+		t.Errorf("final URL = %s, want the JavaScript redirect target", finalURL.String())
+	}
+}
@@ -0,0 +1,49 @@
+package requests
+
+import (
+	"context"
+	"net/http"
+)
+
+// requestIDKey is the context key under which the correlation ID of a logical call is kept for its attempts
+type requestIDKey struct{}
+
+// WithRequestID sends a correlation ID in the headerName header (e.g. "X-Request-ID") of every request. idFunc is
+// called once per logical call, such as one GetContents or PostContentsAsBytes, so all retries of that call carry the
+// same ID and can be matched up with the server's logs. The ID is also included in the retry log messages as
+// requestID.
+func WithRequestID(headerName string, idFunc func() string) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.requestIDHeader = headerName
+		r.requestIDFunc = idFunc
+	}
+}
+
+// withRequestID returns ctx carrying a fresh correlation ID, unless WithRequestID is not configured or ctx already
+// carries the ID of the logical call it is part of.
+func (r *RetryRequest) withRequestID(ctx context.Context) context.Context {
+	if r.requestIDFunc == nil || requestIDFrom(ctx) != "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, r.requestIDFunc())
+}
+
+// setRequestID adds the correlation ID carried by ctx, if any, to header
+func (r *RetryRequest) setRequestID(ctx context.Context, header http.Header) {
+	if id := requestIDFrom(ctx); id != "" {
+		header.Set(r.requestIDHeader, id)
+	}
+}
+
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDLogArgs returns the slog arguments identifying the logical call of ctx, or none without a correlation ID
+func requestIDLogArgs(ctx context.Context) []any {
+	if id := requestIDFrom(ctx); id != "" {
+		return []any{"requestID", id}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// The phases a failed request can have reached, as reported by RequestPhase. Only a request that failed in
+// ErrPhaseSetup is certain not to have reached the server, and so safe to retry even when it is not idempotent.
+var (
+	// ErrPhaseSetup means the request was never sent, e.g. because of an invalid URL, a context cancelled while
+	// waiting for the rate limiter or a concurrency slot, or a connection that could not be dialed.
+	ErrPhaseSetup = errors.New("request was not sent")
+	// ErrPhaseInFlight means the request was sent, or may have been, but no response arrived, e.g. on a timeout or a
+	// connection reset.
+	ErrPhaseInFlight = errors.New("request failed in flight")
+	// ErrPhaseResponse means the server responded, but with an error status or a body that could not be read.
+	ErrPhaseResponse = errors.New("request failed after a response")
+)
+
+// PhaseError is an error from the request methods classified by the furthest phase any attempt of the request reached.
+// errors.Is matches both Phase and the underlying error.
+type PhaseError struct {
+	Phase error
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PhaseError) Unwrap() []error {
+	return []error{e.Phase, e.Err}
+}
+
+// RequestPhase returns the phase a failed request reached, ErrPhaseSetup, ErrPhaseInFlight or ErrPhaseResponse, or nil
+// if err is not classified.
+func RequestPhase(err error) error {
+	var phaseErr *PhaseError
+	if errors.As(err, &phaseErr) {
+		return phaseErr.Phase
+	}
+	return nil
+}
+
+// phaseKey is the context key of the phaseTracker of a logical request
+type phaseKey struct{}
+
+// phaseTracker records the furthest phase reached by the attempts of a logical request
+type phaseTracker struct {
+	phase error
+}
+
+// withPhaseTracker returns ctx carrying a new phaseTracker, and the tracker
+func withPhaseTracker(ctx context.Context) (context.Context, *phaseTracker) {
+	tracker := &phaseTracker{phase: ErrPhaseSetup}
+	return context.WithValue(ctx, phaseKey{}, tracker), tracker
+}
+
+// recordPhase records the outcome of sending a request under ctx, if ctx carries a phaseTracker
+func recordPhase(ctx context.Context, resp *http.Response, err error) {
+	tracker, ok := ctx.Value(phaseKey{}).(*phaseTracker)
+	if !ok {
+		return
+	}
+	switch {
+	case err == nil && resp != nil:
+		tracker.phase = ErrPhaseResponse
+	case tracker.phase == ErrPhaseResponse || neverSent(err):
+	default:
+		tracker.phase = ErrPhaseInFlight
+	}
+}
+
+// wrap classifies err by the phase reached so far
+func (t *phaseTracker) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PhaseError{Phase: t.phase, Err: err}
+}
+
+// neverSent reports whether err means no connection was established, so nothing of the request was written
+func neverSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,69 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestPhase(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	// Accepts connections but never answers, so requests time out after being sent
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	silent := "http://" + listener.Addr().String()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(2, 0), WithRequestTimeout(100*time.Millisecond))
+	tests := []struct {
+		name string
+		ctx  context.Context
+		url  string
+		want error
+	}{
+		{"invalid URL", context.Background(), "ftp://example.com", ErrPhaseSetup},
+		{"connection refused", context.Background(), refusedURL(t), ErrPhaseSetup},
+		{"cancelled before sending", cancelled, unavailable.URL, ErrPhaseSetup},
+		{"timeout", context.Background(), silent, ErrPhaseInFlight},
+		{"error status", context.Background(), unavailable.URL, ErrPhaseResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := r.GetResponse(tt.ctx, tt.url)
+			if got := RequestPhase(err); got != tt.want {
+				t.Errorf("GET phase = %v, want %v (err: %v)", got, tt.want, err)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+
+			_, _, err = r.sendPostRequest(tt.ctx, tt.url, strings.NewReader("{}"))
+			if got := RequestPhase(err); got != tt.want {
+				t.Errorf("POST phase = %v, want %v (err: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
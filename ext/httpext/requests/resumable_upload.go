@@ -0,0 +1,200 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// TusVersion is the tus protocol version sent in the Tus-Resumable header of resumable uploads.
+const TusVersion = "1.0.0"
+
+// ErrUploadStalled is returned by UploadResumable when the server's offset stops advancing.
+var ErrUploadStalled = errors.New("resumable upload stalled")
+
+// errUploadOffsetConflict reports a 409 Conflict: the chunk was sent at an offset the server is no longer at, typically
+// because an earlier attempt reached it but its response was lost.
+var errUploadOffsetConflict = errors.New("upload offset conflict")
+
+// UploadResumable uploads size bytes read from src to url, an upload resource created beforehand, using the tus 1.0
+// core protocol: the server's offset is queried with HEAD, and the data from there on is sent in PATCH requests of at
+// most chunkSize bytes, each carrying Upload-Offset and a matching Content-Range. Each chunk is retried with backoff
+// like any other request; when a chunk still fails, or the server reports a different offset, the offset is queried
+// again and the upload resumes from wherever the server actually got to, so nothing it already has is sent twice. It
+// gives up with ErrUploadStalled once the configured number of attempts pass without the offset advancing.
+func (r *RetryRequest) UploadResumable(ctx context.Context, url string, src io.ReaderAt, size int64, chunkSize int64) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+	if err := validateURL(url); err != nil {
+		return err
+	}
+	ctx = r.withRequestID(ctx)
+
+	offset, err := r.uploadOffset(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	chunk := make([]byte, min(chunkSize, size))
+	stalls := 0
+	for offset < size {
+		n := min(chunkSize, size-offset)
+		if read, err := src.ReadAt(chunk[:n], offset); err != nil && !(errors.Is(err, io.EOF) && int64(read) == n) {
+			return fmt.Errorf("error reading upload data at offset %d: %w", offset, err)
+		}
+
+		newOffset, err := r.uploadChunk(ctx, url, offset, size, chunk[:n])
+		if err == nil && newOffset > offset {
+			offset = newOffset
+			stalls = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		stalls++
+		if stalls >= r.maxRetries {
+			if err == nil {
+				err = fmt.Errorf("server stayed at offset %d", newOffset)
+			}
+			return fmt.Errorf("%w at offset %d of %d for %s: %w", ErrUploadStalled, offset, size, url, err)
+		}
+		r.logRetry(ctx, "Resuming upload", "url", url, "offset", offset, "size", size, "error", err)
+
+		if offset, err = r.uploadOffset(ctx, url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadOffset asks the server how many bytes of the upload at url it has received
+func (r *RetryRequest) uploadOffset(ctx context.Context, url string) (int64, error) {
+	resp, err := r.sendUploadRequest(ctx, http.MethodHead, url, nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error querying upload offset of %s: %w", url, err)
+	}
+	defer closeResponseBody(resp.Body)
+
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Upload-Offset from %s: %w", url, err)
+	}
+	return offset, nil
+}
+
+// uploadChunk sends chunk as the data at offset of a size byte upload and returns the server's new offset
+func (r *RetryRequest) uploadChunk(ctx context.Context, url string, offset, size int64, chunk []byte) (int64, error) {
+	header := http.Header{
+		"Content-Type":  {"application/offset+octet-stream"},
+		"Upload-Offset": {strconv.FormatInt(offset, 10)},
+		"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, size)},
+	}
+	resp, err := r.sendUploadRequest(ctx, http.MethodPatch, url, header, chunk)
+	if err != nil {
+		return 0, err
+	}
+	defer closeResponseBody(resp.Body)
+
+	// Servers that do not echo the offset have taken the whole chunk
+	if value := resp.Header.Get("Upload-Offset"); value != "" {
+		return strconv.ParseInt(value, 10, 64)
+	}
+	return offset + int64(len(chunk)), nil
+}
+
+// sendUploadRequest sends a single tus request with retries and backoff on network errors, 429 and 5xx responses. A
+// 409 Conflict is returned at once as errUploadOffsetConflict, since retrying the same offset cannot succeed.
+func (r *RetryRequest) sendUploadRequest(ctx context.Context, method, url string, extra http.Header, body []byte) (*http.Response, error) {
+	if r.isRateLimited && r.dryRun == nil {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < r.maxRetries; i++ {
+		resp, err = r.attemptUploadRequest(ctx, method, url, extra, body)
+		if err == nil {
+			switch {
+			case resp.StatusCode >= 200 && resp.StatusCode < 300:
+				r.recordAttempt(true, url, resp, nil)
+				return resp, nil
+			case resp.StatusCode == http.StatusConflict:
+				drainAndCloseBody(resp.Body)
+				return nil, fmt.Errorf("%w: %s", errUploadOffsetConflict, url)
+			case resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500:
+				drainAndCloseBody(resp.Body)
+				return nil, &StatusCodeError{StatusCode: resp.StatusCode, URL: url, Message: resp.Status}
+			}
+		}
+
+		if resp != nil {
+			drainAndCloseBody(resp.Body)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if budgetErr := r.recordAttempt(false, url, resp, err); budgetErr != nil && i < r.maxRetries-1 {
+			return nil, budgetErr
+		}
+		if i < r.maxRetries-1 {
+			if err := r.backoff(ctx, i, url, err, resp); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err == nil && resp != nil {
+		err = &StatusCodeError{StatusCode: resp.StatusCode, URL: url, Message: resp.Status}
+	}
+	r.logGiveUp(ctx, url, err)
+	return nil, fmt.Errorf("max retries reached: last error: %w", err)
+}
+
+func (r *RetryRequest) attemptUploadRequest(ctx context.Context, method, url string, extra http.Header, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.requestTimeout)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	header, err := r.requestHeaders(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for key, values := range extra {
+		header[key] = values
+	}
+	header.Set("Tus-Resumable", TusVersion)
+	req.Header = header
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The responses carry no body worth reading, so the request context can go once the body is closed
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a request's context when its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
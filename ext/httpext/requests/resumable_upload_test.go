@@ -0,0 +1,104 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// tusServer is an in-memory tus upload resource. failPatch is consulted for every PATCH with the number of PATCHes
+// received so far; it can reject the request outright, or store the chunk and then fail as if the response was lost.
+type tusServer struct {
+	mu        sync.Mutex
+	data      []byte
+	patches   int
+	failPatch func(patch int) (reject, loseResponse bool)
+}
+
+func (s *tusServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Header.Get("Tus-Resumable") != TusVersion {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	switch req.Method {
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		s.patches++
+		reject, loseResponse := false, false
+		if s.failPatch != nil {
+			reject, loseResponse = s.failPatch(s.patches)
+		}
+		if reject {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if req.Header.Get("Upload-Offset") != strconv.Itoa(len(s.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		s.data = append(s.data, body...)
+		if loseResponse {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestUploadResumable(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789"), 25)
+
+	tests := []struct {
+		name      string
+		existing  int
+		failPatch func(patch int) (bool, bool)
+	}{
+		{"clean", 0, nil},
+		{"resumes from server offset", 130, nil},
+		{"retries rejected chunk", 0, func(patch int) (bool, bool) { return patch == 2, false }},
+		{"recovers from lost response", 0, func(patch int) (bool, bool) { return false, patch == 3 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tus := &tusServer{data: append([]byte(nil), payload[:tt.existing]...), failPatch: tt.failPatch}
+			server := httptest.NewServer(tus)
+			defer server.Close()
+
+			r := NewRetryRequest(WithAttemptsAndBackoff(3, 0))
+			err := r.UploadResumable(context.Background(), server.URL, bytes.NewReader(payload), int64(len(payload)), 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(tus.data, payload) {
+				t.Errorf("server received %q, want %q", tus.data, payload)
+			}
+		})
+	}
+}
+
+func TestUploadResumableStalls(t *testing.T) {
+	tus := &tusServer{failPatch: func(int) (bool, bool) { return true, false }}
+	server := httptest.NewServer(tus)
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(2, 0))
+	err := r.UploadResumable(context.Background(), server.URL, bytes.NewReader([]byte("data")), 4, 2)
+	if !errors.Is(err, ErrUploadStalled) {
+		t.Fatalf("err = %v, want ErrUploadStalled", err)
+	}
+}
@@ -0,0 +1,96 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// DefaultRetryBudgetMaxTokens is the token capacity of budgets created by WithSharedRetryBudget.
+const DefaultRetryBudgetMaxTokens = 10
+
+// ErrRetryBudgetExhausted is returned, wrapping the last attempt's error, when a failed request is not retried
+// because its retry budget has run out.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudget throttles retries across every request that shares it, following gRPC's retry throttling: the budget
+// holds up to maxTokens tokens and starts full; each failed attempt takes one token and each success returns ratio
+// tokens. Retries are only attempted while more than half the tokens remain, so during a sustained outage requests
+// fail fast after their first attempt instead of multiplying the load on the upstream, and retries resume once enough
+// requests succeed again. A RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// NewRetryBudget creates a full budget of maxTokens tokens, refilled by ratio tokens per successful request. Share it
+// between clients with WithRetryBudget.
+func NewRetryBudget(maxTokens int, ratio float64) *RetryBudget {
+	return &RetryBudget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+// WithSharedRetryBudget throttles retries for all requests made through this client with a budget of
+// DefaultRetryBudgetMaxTokens tokens, refilled by ratio tokens per success (gRPC uses 0.1: one retry earned per ten
+// successes once throttled). See RetryBudget.
+func WithSharedRetryBudget(ratio float64) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.retryBudget = NewRetryBudget(DefaultRetryBudgetMaxTokens, ratio)
+	}
+}
+
+// WithRetryBudget throttles retries using budget, which may be shared with other clients so that they back off
+// together when a common upstream struggles.
+func WithRetryBudget(budget *RetryBudget) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.retryBudget = budget
+	}
+}
+
+// Tokens returns the number of tokens currently in the budget.
+func (b *RetryBudget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// recordSuccess returns ratio tokens to the budget.
+func (b *RetryBudget) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.maxTokens, b.tokens+b.ratio)
+}
+
+// recordFailure takes a token for a failed attempt and reports whether the attempt may still be retried.
+func (b *RetryBudget) recordFailure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = max(0, b.tokens-1)
+	return b.tokens > b.maxTokens/2
+}
+
+// recordAttempt updates the retry budget, if any, with the outcome of an attempt. For a failed attempt it returns
+// ErrRetryBudgetExhausted, wrapping the attempt's error, when the budget no longer allows a retry.
+func (r *RetryRequest) recordAttempt(succeeded bool, url string, resp *http.Response, err error) error {
+	if r.retryBudget == nil {
+		return nil
+	}
+	if succeeded {
+		r.retryBudget.recordSuccess()
+		return nil
+	}
+	if r.retryBudget.recordFailure() {
+		return nil
+	}
+
+	if err == nil && resp != nil {
+		err = &StatusCodeError{StatusCode: resp.StatusCode, URL: url, Message: resp.Status}
+	}
+	return fmt.Errorf("%w: %s: last error: %w", ErrRetryBudgetExhausted, url, err)
+}
@@ -0,0 +1,65 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSharedRetryBudgetFailsFast(t *testing.T) {
+	var hits atomic.Int64
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	budget := NewRetryBudget(4, 1)
+	first := NewRetryRequest(WithAttemptsAndBackoff(5, 0), WithRetryBudget(budget))
+	second := NewRetryRequest(WithAttemptsAndBackoff(5, 0), WithRetryBudget(budget))
+
+	// 4 tokens allow retries while more than 2 remain: the first request stops after its second failed attempt
+	_, _, err := first.GetResponse(context.Background(), server.URL)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("err = %v, want ErrRetryBudgetExhausted", err)
+	}
+	var statusErr *StatusCodeError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want it to wrap the 503", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("server hit %d times, want 2", got)
+	}
+
+	// The budget is shared, so the second client fails after a single attempt
+	hits.Store(0)
+	if _, _, err := second.GetResponse(context.Background(), server.URL); !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("second client err = %v, want ErrRetryBudgetExhausted", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("second client hit server %d times, want 1", got)
+	}
+
+	// Successes refill the budget
+	failing.Store(false)
+	for i := 0; i < 4; i++ {
+		resp, cancel, err := second.GetResponse(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("GetResponse: %v", err)
+		}
+		drainAndCloseBody(resp.Body)
+		cancel()
+	}
+	if got := budget.Tokens(); got != 4 {
+		t.Fatalf("budget tokens = %v after successes, want 4", got)
+	}
+}
@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithRetryLogEvery logs only the first and then every nth retry made through the RetryRequest, counted across all
+// calls, so a sustained outage hitting many URLs does not flood the logs. Giving up on a request is always logged.
+func WithRetryLogEvery(n int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.retryLogEvery = n
+	}
+}
+
+// WithRetryLogLevel sets the level retries are logged at, slog.LevelInfo by default; e.g. slog.LevelDebug keeps them
+// out of normal logs. Giving up on a request is still logged at slog.LevelWarn.
+func WithRetryLogLevel(level slog.Level) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.retryLogLevel = level
+	}
+}
+
+// logRetry logs a retry at the configured level, unless WithRetryLogEvery throttles it
+func (r *RetryRequest) logRetry(ctx context.Context, msg string, args ...any) {
+	if r.retryLogEvery > 1 && (r.retryLogCount.Add(1)-1)%int64(r.retryLogEvery) != 0 {
+		return
+	}
+	slog.Log(ctx, r.retryLogLevel, msg, append(args, requestIDLogArgs(ctx)...)...)
+}
+
+// logGiveUp logs that a request failed after all of its attempts
+func (r *RetryRequest) logGiveUp(ctx context.Context, url string, err error) {
+	slog.Warn("Giving up on request after max retries",
+		append([]any{"url", url, "maxRetries", r.maxRetries, "error", err}, requestIDLogArgs(ctx)...)...)
+}
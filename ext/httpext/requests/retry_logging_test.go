@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureLogs sends the default logger's output at level and above to the returned buffer for the rest of the test
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestRetryLogEvery(t *testing.T) {
+	logs := captureLogs(t, slog.LevelDebug)
+
+	r := NewRetryRequest(WithRetryLogEvery(3), WithRetryLogLevel(slog.LevelDebug))
+	for i := 0; i < 7; i++ {
+		r.logRetry(context.Background(), "Retrying request after backoff", "attempt", i+1)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("logged %d lines, want 3 (attempts 1, 4 and 7):\n%s", len(lines), logs)
+	}
+	for i, attempt := range []string{"attempt=1", "attempt=4", "attempt=7"} {
+		if !strings.Contains(lines[i], "level=DEBUG") || !strings.Contains(lines[i], attempt) {
+			t.Errorf("line %d = %q, want a DEBUG line with %s", i, lines[i], attempt)
+		}
+	}
+}
+
+func TestGiveUpLoggedDespiteRetryLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	logs := captureLogs(t, slog.LevelInfo)
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(3, 0), WithRetryLogLevel(slog.LevelDebug))
+	if _, _, err := r.GetResponse(context.Background(), server.URL); err == nil {
+		t.Fatal("expected an error after exhausting the retries")
+	}
+
+	if strings.Contains(logs.String(), "Retrying request") {
+		t.Errorf("retries were logged above debug level:\n%s", logs)
+	}
+	if !strings.Contains(logs.String(), "level=WARN msg=\"Giving up on request after max retries\"") {
+		t.Errorf("missing the give-up warning:\n%s", logs)
+	}
+}
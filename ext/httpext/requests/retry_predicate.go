@@ -0,0 +1,69 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// retryPredicatePeekSize is the most of a response body a retry predicate is able to read.
+const retryPredicatePeekSize = 64 * 1024
+
+// WithRetryPredicate overrides the default retry decision in GetResponse (retry unless 2xx, a 404 with WithNoRetry404,
+// or a status excluded by WithRetryableStatuses or WithNonRetryableStatuses). The predicate is called after every
+// attempt with the response, which may be nil, and the error, and returns true to retry. When it returns false the
+// response is handed to the caller as is, even for a non-2xx status, and an error is returned as is without further
+// attempts.
+//
+// The predicate may read up to the first 64KB of resp.Body, e.g. to detect APIs that report errors inside a 200
+// response. It reads the body decompressed according to its Content-Encoding, or as sent if the encoding is not
+// supported; afterwards the raw body is restored, so the caller still reads all of it as GetResponse returns it. The
+// predicate must not close the body.
+func WithRetryPredicate(predicate func(resp *http.Response, err error) (retry bool)) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.retryPredicate = predicate
+	}
+}
+
+// peekedBody is a response body whose first bytes have already been read and are replayed before the rest.
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// checkRetryPredicate runs the configured retry predicate, letting it peek at the response body without consuming it.
+func (r *RetryRequest) checkRetryPredicate(resp *http.Response, err error) bool {
+	if resp == nil || resp.Body == nil {
+		return r.retryPredicate(resp, err)
+	}
+
+	// Every raw byte the decompressor reads, including any it reads ahead, is kept so it can be replayed
+	original := resp.Body
+	var raw bytes.Buffer
+	resp.Body = io.NopCloser(io.TeeReader(original, &raw))
+	peek, readErr := peekDecoded(resp)
+	resp.Body = &peekedBody{Reader: io.MultiReader(&raw, original), Closer: original}
+	if readErr != nil {
+		slog.Warn("Failed to read response body for retry predicate, retrying", "err", readErr)
+		return true
+	}
+
+	body := resp.Body
+	resp.Body = io.NopCloser(bytes.NewReader(peek))
+	retry := r.retryPredicate(resp, err)
+	resp.Body = body
+
+	return retry
+}
+
+// peekDecoded reads up to retryPredicatePeekSize bytes of the decompressed body of resp, or of the raw body if its
+// encoding is not supported
+func peekDecoded(resp *http.Response) ([]byte, error) {
+	reader, release, err := decompressBody(resp)
+	if err != nil {
+		reader, release = resp.Body, func() {}
+	}
+	defer release()
+	return io.ReadAll(io.LimitReader(reader, retryPredicatePeekSize))
+}
@@ -0,0 +1,111 @@
+package requests
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRetryPredicatePeeksDecompressedBody(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"status": "ok", "padding": "` + strings.Repeat("x", 100_000) + `"}`
+		if attempts.Add(1) == 1 {
+			body = `{"status": "error"}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(body))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	var peeked []string
+	r := NewRetryRequest(WithAttemptsAndBackoff(3, 0), WithRetryPredicate(func(resp *http.Response, err error) bool {
+		if err != nil {
+			return true
+		}
+		peek, _ := io.ReadAll(resp.Body)
+		peeked = append(peeked, string(peek))
+		return strings.Contains(string(peek), `"error"`)
+	}))
+
+	resp, cancel, err := r.GetResponse(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	if attempts.Load() != 2 {
+		t.Errorf("attempts = %d, want 2", attempts.Load())
+	}
+	if len(peeked) != 2 || peeked[0] != `{"status": "error"}` || len(peeked[1]) != retryPredicatePeekSize {
+		t.Errorf("predicate did not see the decompressed bodies: %d peeks", len(peeked))
+	}
+
+	// The caller still gets the raw body, whole
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("body returned to the caller is not the raw gzip stream: %v", err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(body), `{"status": "ok"`) || !strings.HasSuffix(string(body), `"}`) {
+		t.Errorf("body was not restored in full: %d bytes", len(body))
+	}
+}
+
+func TestRetryPredicateOverridesDefault(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unavailable" {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// An API reporting its error inside a 200 response until the third attempt
+		if attempts.Add(1) < 3 {
+			w.Write([]byte(`{"status": "error"}`))
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(5, 0), WithRetryPredicate(func(resp *http.Response, err error) bool {
+		if err != nil || resp.StatusCode == http.StatusServiceUnavailable {
+			return false
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return strings.Contains(string(body), `"error"`)
+	}))
+
+	body, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"status": "ok"}` || attempts.Load() != 3 {
+		t.Errorf("got %q after %d attempts, want the ok body after 3", body, attempts.Load())
+	}
+
+	// A status the default would retry is handed over as is when the predicate says not to retry
+	attempts.Store(0)
+	resp, cancel, err := r.GetResponse(context.Background(), server.URL+"/unavailable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drainAndCloseBody(resp.Body)
+	cancel()
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts.Load() != 1 {
+		t.Errorf("got status %d after %d attempts, want 503 after 1", resp.StatusCode, attempts.Load())
+	}
+}
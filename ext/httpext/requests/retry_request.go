@@ -1,22 +1,34 @@
 package requests
 
 import (
-	"compress/gzip"
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"golang.org/x/net/html/charset"
+	"golang.org/x/sync/semaphore"
 	"golang.org/x/time/rate"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
+	"vmuser/ext/httpext"
 )
 
+// ErrNetworkUnavailableAfterMaxWait is returned when, with WithNetworkRetryPolicy, the network stays unavailable for
+// the maximum wait or the maximum number of recovery attempts (WithNetworkRecoveryMaxAttempts).
 var ErrNetworkUnavailableAfterMaxWait = errors.New("network unavailable after max wait")
 
+// errReadingBody marks failures that happen while reading a response body, after GetResponse has already succeeded.
+// Only these are retried by fetchContentsAsBytes; failures inside GetResponse have been retried there already.
+var errReadingBody = errors.New("error reading response body")
+
 type StatusCodeError struct {
 	StatusCode int
 	URL        string
@@ -41,6 +53,7 @@ const (
 	DefaultRequestTimeout            = 60 * time.Second
 	DefaultNetworkUnavailableBackOff = 5 * time.Minute
 	DefaultNetworkUnavailableMaxWait = 6 * time.Hour
+	DefaultMaxLineSize               = bufio.MaxScanTokenSize
 )
 
 // RetryRequest struct encapsulates configuration for making HTTP requests with retry and rate limiting functionality.
@@ -53,12 +66,42 @@ type RetryRequest struct {
 	isRateLimited    bool
 	requestTimeout   time.Duration
 	noRetry404       bool
-	noRetry422       bool
 	longBackOffOn429 time.Duration
+	fallbackOn404    bool
+	maxLineSize      int
+
+	authorization   string
+	bearerTokenFunc func() (string, error)
+
+	retryPredicate     func(resp *http.Response, err error) bool
+	wireLogMaxBytes    int
+	maxMetaRefreshHops int
+	dryRun             *dryRunTransport
+	acceptLanguage     string
+	acceptEncoding     string
+	forcedCharset      string
+	concurrency        *semaphore.Weighted
+	singleFlight       *singleFlightGroup
+	retryBudget        *RetryBudget
+	requestIDHeader    string
+	requestIDFunc      func() string
+	rejectPartial      bool
+	clock              Clock
+	retryLogEvery      int
+	retryLogLevel      slog.Level
+	retryLogCount      atomic.Int64
+	idempotencyKeyFunc func() string
+
+	retryableStatuses    map[int]bool
+	nonRetryableStatuses map[int]bool
 
 	resolveNetworkUnavailable bool
 	networkUnavailableBackOff time.Duration
 	networkUnavailableMaxWait time.Duration
+	networkProbeURLs          []string
+	networkProbeTimeout       time.Duration
+	networkRecoveryMaxBackOff time.Duration
+	networkRecoveryAttempts   int
 }
 
 // RetryRequestOption represents a functional option type for configuring the RetryRequest.
@@ -71,6 +114,32 @@ func WithHeaders(headers http.Header) RetryRequestOption {
 	}
 }
 
+// WithBasicAuth sets the Authorization header of every request to HTTP Basic credentials for user and pass.
+func WithBasicAuth(user, pass string) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+		r.bearerTokenFunc = nil
+	}
+}
+
+// WithBearerToken sets the Authorization header of every request to the given bearer token.
+func WithBearerToken(token string) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.authorization = "Bearer " + token
+		r.bearerTokenFunc = nil
+	}
+}
+
+// WithBearerTokenFunc sets the Authorization header of every request to a bearer token obtained from tokenFunc, which
+// is called for each attempt so that expiring tokens (e.g. OAuth access tokens) can be refreshed. An error from
+// tokenFunc fails the attempt.
+func WithBearerTokenFunc(tokenFunc func() (string, error)) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.authorization = ""
+		r.bearerTokenFunc = tokenFunc
+	}
+}
+
 // WithRequestTimeout provides custom request timeout for the HTTP request.
 func WithRequestTimeout(requestTimeout time.Duration) RetryRequestOption {
 	return func(r *RetryRequest) {
@@ -86,7 +155,9 @@ func WithAttemptsAndBackoff(attempts int, backoff time.Duration) RetryRequestOpt
 	}
 }
 
-// WithRateLimiting configures rate limiting for the HTTP requests.
+// WithRateLimiting configures rate limiting for the HTTP requests. Every request method waits for the limiter under
+// the caller's context, so cancelling it aborts the wait at once with the context's error; the methods without a
+// context parameter wait without a bound.
 func WithRateLimiting(limit rate.Limit, burst int) RetryRequestOption {
 	return func(r *RetryRequest) {
 		r.limiter = rate.NewLimiter(limit, burst)
@@ -101,11 +172,10 @@ func WithNoRetry404() RetryRequestOption {
 	}
 }
 
-// WithNoRetry422 configures the request to not retry on 422 Unprocessable Entity errors.
+// WithNoRetry422 configures the request to not retry on 422 Unprocessable Entity errors. It is shorthand for
+// WithNonRetryableStatuses(http.StatusUnprocessableEntity).
 func WithNoRetry422() RetryRequestOption {
-	return func(r *RetryRequest) {
-		r.noRetry422 = true
-	}
+	return WithNonRetryableStatuses(http.StatusUnprocessableEntity)
 }
 
 // WithNetworkRetryPolicy configures the backoff delay and maximum wait time for retrying requests when
@@ -119,6 +189,32 @@ func WithNetworkRetryPolicy(networkUnavailableBackOff time.Duration, maxWaitTime
 	}
 }
 
+// WithNetworkProbe configures the URLs probed in parallel, and the timeout for probing them, when deciding whether
+// the network is completely unavailable. Only used together with WithNetworkRetryPolicy.
+func WithNetworkProbe(urls []string, timeout time.Duration) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.networkProbeURLs = urls
+		r.networkProbeTimeout = timeout
+	}
+}
+
+// WithNetworkRecoveryBackOffGrowth doubles the delay between recovery attempts while the network is unavailable,
+// starting from the backoff of WithNetworkRetryPolicy, up to maxBackOff. Without it the delay stays constant.
+func WithNetworkRecoveryBackOffGrowth(maxBackOff time.Duration) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.networkRecoveryMaxBackOff = maxBackOff
+	}
+}
+
+// WithNetworkRecoveryMaxAttempts limits the number of recovery attempts made while the network is unavailable, in
+// addition to the maximum wait of WithNetworkRetryPolicy; whichever is reached first ends the recovery with
+// ErrNetworkUnavailableAfterMaxWait. Zero, the default, means no limit.
+func WithNetworkRecoveryMaxAttempts(attempts int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.networkRecoveryAttempts = attempts
+	}
+}
+
 // WithLongBackOffOn429 configures the backoff delay for retrying requests when a 429 Too Many Requests status code is received.
 func WithLongBackOffOn429(backoff time.Duration) RetryRequestOption {
 	return func(r *RetryRequest) {
@@ -126,6 +222,49 @@ func WithLongBackOffOn429(backoff time.Duration) RetryRequestOption {
 	}
 }
 
+// WithMaxLineSize configures the longest line GetLines will accept before failing with bufio.ErrTooLong.
+func WithMaxLineSize(maxLineSize int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.maxLineSize = maxLineSize
+	}
+}
+
+// WithFollowMetaRefresh configures the GetContents family to follow meta-refresh and JavaScript location.replace
+// redirects found in successful text/html responses, up to maxHops times, returning the content of the final page.
+// Relative targets are resolved against the URL of the page containing the redirect.
+func WithFollowMetaRefresh(maxHops int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.maxMetaRefreshHops = maxHops
+	}
+}
+
+// WithAcceptLanguage sets the Accept-Language header of every request to the given language tags, most preferred
+// first. Quality weights are assigned in decreasing order, e.g. "en-US", "en", "fr" becomes
+// "en-US, en;q=0.9, fr;q=0.8".
+func WithAcceptLanguage(tags ...string) RetryRequestOption {
+	return func(r *RetryRequest) {
+		values := make([]string, 0, len(tags))
+		for i, tag := range tags {
+			if i == 0 {
+				values = append(values, tag)
+				continue
+			}
+			q := max(10-i, 1)
+			values = append(values, fmt.Sprintf("%s;q=0.%d", tag, q))
+		}
+		r.acceptLanguage = strings.Join(values, ", ")
+	}
+}
+
+// WithForcedCharset decodes textual responses using the named charset (e.g. "windows-1252") regardless of the charset
+// declared by the server, for servers that mislabel their content. A byte order mark in the body still takes
+// precedence.
+func WithForcedCharset(name string) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.forcedCharset = name
+	}
+}
+
 // WithLoggedRedirects configures the request to log redirects using slog.
 func WithLoggedRedirects() RetryRequestOption {
 	return func(r *RetryRequest) {
@@ -143,7 +282,12 @@ func NewRetryRequest(options ...RetryRequestOption) *RetryRequest {
 		maxRetries:     DefaultMaxRetries,
 		backoffFactor:  DefaultBackoffFactor,
 		requestTimeout: DefaultRequestTimeout,
+		maxLineSize:    DefaultMaxLineSize,
 		client:         &http.Client{},
+		clock:          realClock{},
+
+		networkProbeURLs:    DefaultNetworkProbeURLs,
+		networkProbeTimeout: DefaultNetworkProbeTimeout,
 	}
 
 	r.headers.Set("User-Agent", DefaultUserAgent)
@@ -152,26 +296,142 @@ func NewRetryRequest(options ...RetryRequestOption) *RetryRequest {
 		opt(r)
 	}
 
+	r.disableTransportCompression()
+
+	if r.dryRun != nil {
+		r.client.Transport = r.dryRun
+	}
+
+	if r.wireLogMaxBytes > 0 {
+		next := r.client.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		r.client.Transport = &wireLoggingTransport{next: next, maxBytes: r.wireLogMaxBytes}
+	}
+
 	return r
 }
 
-func (r *RetryRequest) createRequestAndGetResponse(ctx context.Context, url string) (*http.Response, context.CancelFunc, error) {
+// Close releases the resources held by the client: idle keep-alive connections of its transport are closed. Clients
+// using the shared http.DefaultTransport (no transport options set) leave it untouched. Close does not interrupt
+// requests in flight, and the client remains usable afterwards; new connections are simply dialed as needed.
+func (r *RetryRequest) Close() {
+	if r.client.Transport == nil {
+		return
+	}
+	r.client.CloseIdleConnections()
+}
+
+// requestHeaders returns a per-request copy of the configured headers with authorization applied, so that changes made
+// for one request never leak into the shared configuration.
+func (r *RetryRequest) requestHeaders(ctx context.Context) (http.Header, error) {
+	header := r.headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	if r.bearerTokenFunc != nil {
+		token, err := r.bearerTokenFunc()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bearer token: %w", err)
+		}
+		header.Set("Authorization", "Bearer "+token)
+	} else if r.authorization != "" {
+		header.Set("Authorization", r.authorization)
+	}
+
+	if r.acceptLanguage != "" {
+		header.Set("Accept-Language", r.acceptLanguage)
+	}
+
+	// An explicit Accept-Encoding stops net/http from transparently decompressing behind decodeBody's back
+	if r.acceptEncoding != "" {
+		header.Set("Accept-Encoding", r.acceptEncoding)
+	} else if header.Get("Accept-Encoding") == "" {
+		header.Set("Accept-Encoding", DefaultAcceptEncoding)
+	}
+
+	r.setRequestID(ctx, header)
+
+	return header, nil
+}
+
+// getOptions adjusts a single GET made through getResponse, e.g. for conditional requests.
+type getOptions struct {
+	// header is added to the configured headers for this request only
+	header http.Header
+	// acceptNotModified treats a 304 Not Modified response as final rather than retrying it
+	acceptNotModified bool
+}
+
+// isFinal reports whether a response with statusCode ends the request successfully.
+func (o getOptions) isFinal(statusCode int) bool {
+	return (statusCode >= 200 && statusCode < 300) || (o.acceptNotModified && statusCode == http.StatusNotModified)
+}
+
+func (r *RetryRequest) createRequestAndGetResponse(ctx context.Context, url string, opts getOptions) (*http.Response, context.CancelFunc, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.requestTimeout)
 	req, reqErr := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if reqErr != nil {
 		cancel()
 		return nil, nil, reqErr
 	}
-	req.Header = r.headers
+	header, err := r.requestHeaders(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	for key, values := range opts.header {
+		header[key] = values
+	}
+	req.Header = header
+	if err := ctx.Err(); err != nil {
+		cancel()
+		return nil, nil, err
+	}
 	resp, err := r.client.Do(req)
+	recordPhase(ctx, resp, err)
 	return resp, cancel, err
 }
 
-// GetResponse sends an HTTP GET request to the specified URL with retries on failures.
+// GetResponse sends an HTTP GET request to the specified URL with retries on failures. A URL that is not http or https
+// with a host fails with ErrUnsupportedURL before anything is sent.
+//
+// resp.Body is the raw body as sent by the server. Requests carry an explicit Accept-Encoding, which stops net/http
+// from decompressing it, so it may be gzip or deflate encoded according to its Content-Encoding header; the
+// GetContents methods decode it for you.
 func (r *RetryRequest) GetResponse(ctx context.Context, url string) (*http.Response, context.CancelFunc, error) {
+	return r.withConcurrencySlot(ctx, func() (*http.Response, context.CancelFunc, error) {
+		return r.getResponse(ctx, url, getOptions{})
+	})
+}
+
+func (r *RetryRequest) getResponse(ctx context.Context, url string, opts getOptions) (*http.Response, context.CancelFunc, error) {
+	if err := validateURL(url); err != nil {
+		return nil, nil, &PhaseError{Phase: ErrPhaseSetup, Err: err}
+	}
+
+	ctx, tracker := withPhaseTracker(ctx)
+	resp, cancel, err := r.getResponseWithRetries(ctx, url, opts)
+	if err == nil && r.rejectPartial {
+		if partialErr := unexpectedPartial(resp, url); partialErr != nil {
+			drainAndCloseBody(resp.Body)
+			if cancel != nil {
+				cancel()
+			}
+			return nil, nil, tracker.wrap(partialErr)
+		}
+	}
+	return resp, cancel, tracker.wrap(err)
+}
+
+func (r *RetryRequest) getResponseWithRetries(ctx context.Context, url string, opts getOptions) (*http.Response, context.CancelFunc, error) {
+	ctx = r.withRequestID(ctx)
+
 	// Note, this rate limiter is at the start of the request. This works as a general rule so long as the backoff
 	// time is less than the rate limiter time.
-	if r.isRateLimited {
+	if r.isRateLimited && r.dryRun == nil {
 		err := r.limiter.Wait(ctx)
 		if err != nil {
 			return nil, nil, err
@@ -182,46 +442,72 @@ func (r *RetryRequest) GetResponse(ctx context.Context, url string) (*http.Respo
 	var err error
 	var cancel context.CancelFunc
 	for i := 0; i < r.maxRetries; i++ {
-		resp, cancel, err = r.createRequestAndGetResponse(ctx, url)
-		if err == nil {
+		resp, cancel, err = r.createRequestAndGetResponse(ctx, url, opts)
+		if r.retryPredicate != nil {
+			if !r.checkRetryPredicate(resp, err) {
+				if err != nil {
+					if cancel != nil {
+						cancel()
+					}
+					return nil, nil, err
+				}
+				r.recordAttempt(true, url, resp, nil)
+				return resp, cancel, nil
+			}
+		} else if err == nil {
 			if resp.StatusCode == http.StatusNotFound && r.noRetry404 {
 				return resp, cancel, fmt.Errorf("%w: %s", ErrNotFoundNoRetry, url)
 			}
-			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if opts.isFinal(resp.StatusCode) {
 				// done, return response
+				r.recordAttempt(true, url, resp, nil)
 				return resp, cancel, nil
 			}
+			if !r.isRetryableStatus(resp.StatusCode) {
+				err := statusNotRetried(resp, url)
+				cancel()
+				return nil, nil, err
+			}
 		}
 
-		if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			cancel()
-		}
-
+		// the attempt is being retried; drain before cancelling so the connection can be reused
 		if resp != nil {
-			closeErr := resp.Body.Close()
-			if closeErr != nil {
-				slog.Error("Failed to close response body, potential leak, continuing", "err", closeErr)
-			}
+			drainAndCloseBody(resp.Body)
+		}
+		if cancel != nil {
+			cancel()
 		}
 
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return nil, nil, context.Canceled
 		}
 
+		if budgetErr := r.recordAttempt(false, url, resp, err); budgetErr != nil && i < r.maxRetries-1 {
+			return nil, nil, budgetErr
+		}
+
 		if r.resolveNetworkUnavailable && i == r.maxRetries-1 {
 			// if it is the last attempt, check network if WithNetworkRetryPolicy is set
-			if IsNetworkUnavailable(err, url) {
-				start := time.Now()
-				for {
-					remainingTime := r.networkUnavailableMaxWait - time.Since(start)
-					if remainingTime <= 0 {
+			if isNetworkUnavailable(ctx, err, url, r.networkProbeURLs, r.networkProbeTimeout) {
+				start := r.clock.Now()
+				backOff := r.networkUnavailableBackOff
+				for recoveryAttempt := 0; ; recoveryAttempt++ {
+					remainingTime := r.networkUnavailableMaxWait - r.clock.Now().Sub(start)
+					if remainingTime <= 0 || (r.networkRecoveryAttempts > 0 && recoveryAttempt >= r.networkRecoveryAttempts) {
 						return nil, nil, ErrNetworkUnavailableAfterMaxWait
 					}
 
-					sleepDuration := min(remainingTime, r.networkUnavailableBackOff)
-					time.Sleep(sleepDuration)
+					sleepDuration := min(remainingTime, backOff)
+					select {
+					case <-ctx.Done():
+						return nil, nil, ctx.Err()
+					case <-r.clock.After(sleepDuration):
+					}
+					if r.networkRecoveryMaxBackOff > backOff {
+						backOff = min(2*backOff, r.networkRecoveryMaxBackOff)
+					}
 
-					resp, cancel, err = r.createRequestAndGetResponse(ctx, url)
+					resp, cancel, err = r.createRequestAndGetResponse(ctx, url, opts)
 					if err == nil {
 						if resp.StatusCode == http.StatusNotFound && r.noRetry404 {
 							return resp, cancel, &StatusCodeError{
@@ -230,25 +516,22 @@ func (r *RetryRequest) GetResponse(ctx context.Context, url string) (*http.Respo
 								Message:    ErrNotFound.Message,
 							}
 						}
-						if resp.StatusCode == http.StatusUnprocessableEntity && r.noRetry422 {
-							return resp, cancel, &StatusCodeError{
-								StatusCode: resp.StatusCode,
-								URL:        url,
-								Message:    ErrUnprocessableEntity.Message,
-							}
-						}
-						if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+						if opts.isFinal(resp.StatusCode) {
 							// done, return response
 							return resp, cancel, nil
 						}
+						if !r.isRetryableStatus(resp.StatusCode) {
+							err := statusNotRetried(resp, url)
+							cancel()
+							return nil, nil, err
+						}
 					}
 
-					cancel()
 					if resp != nil {
-						closeErr := resp.Body.Close()
-						if closeErr != nil {
-							slog.Error("Failed to close response body, potential leak, continuing", "err", closeErr)
-						}
+						drainAndCloseBody(resp.Body)
+					}
+					if cancel != nil {
+						cancel()
 					}
 
 					if err != nil {
@@ -275,46 +558,113 @@ func (r *RetryRequest) GetResponse(ctx context.Context, url string) (*http.Respo
 	}
 
 	// If here, all retries failed
+	if err == nil && resp != nil {
+		err = &StatusCodeError{StatusCode: resp.StatusCode, URL: url, Message: resp.Status}
+	}
+	r.logGiveUp(ctx, url, err)
 	return nil, nil, fmt.Errorf("max retries reached: last error: %w", err)
 }
 
 func (r *RetryRequest) fetchContentsAsBytes(ctx context.Context, url string) ([]byte, error) {
+	result, err := r.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return result.Body, nil
+}
+
+// fetch retrieves url through the retry, gzip and charset handling, following meta refresh redirects if configured.
+// Concurrent fetches of the same URL are coalesced when WithSingleFlight is configured.
+func (r *RetryRequest) fetch(ctx context.Context, url string) (*FetchResult, error) {
+	// Retries made while reading the body and meta refresh hops belong to the same logical call
+	ctx = r.withRequestID(ctx)
+	if r.singleFlight == nil {
+		return r.fetchUncoalesced(ctx, url)
+	}
+	return r.fetchSingleFlight(ctx, url)
+}
+
+func (r *RetryRequest) fetchUncoalesced(ctx context.Context, url string) (*FetchResult, error) {
+	bodyBytes, resp, err := r.fetchContents(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	for hop := 0; hop < r.maxMetaRefreshHops; hop++ {
+		if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+			break
+		}
+		target, found := extractJavaScriptRedirect(string(bodyBytes))
+		if !found {
+			break
+		}
+		nextURL, err := resp.Request.URL.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid meta refresh target %q on %s: %w", target, resp.Request.URL, err)
+		}
+
+		slog.Info("Following meta refresh", "from", resp.Request.URL.String(), "to", nextURL.String(), "hop", hop+1)
+		bodyBytes, resp, err = r.fetchContents(ctx, nextURL.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &FetchResult{
+		Body:       bodyBytes,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		FinalURL:   resp.Request.URL.String(),
+		FetchedAt:  r.clock.Now().UTC(),
+	}, nil
+}
+
+// fetchContents retrieves and decodes the body of url, retrying transient failures while reading the body. It also
+// returns the (already closed) response, whose headers and final request URL remain available. Once ctx is cancelled
+// no further attempt is made, even when the backoff between attempts is zero or skipped.
+func (r *RetryRequest) fetchContents(ctx context.Context, url string) ([]byte, *http.Response, error) {
 	var bodyBytes []byte
+	var resp *http.Response
 	var err error
 
 	for attempt := 0; attempt < r.maxRetries; attempt++ {
-		bodyBytes, err = r.attemptFetchContents(ctx, url)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+
+		bodyBytes, resp, err = r.attemptFetchContents(ctx, url)
 		if err == nil {
-			return bodyBytes, nil
+			return bodyBytes, resp, nil
 		}
 
-		if strings.Contains(err.Error(), "stream error") {
-			slog.Info("Encountered stream error, will retry",
+		if errors.Is(err, errReadingBody) && httpext.IsDialError(err) {
+			r.logRetry(ctx, "Encountered transient error reading response, will retry",
 				"url", url,
 				"attempt", attempt+1,
 				"maxRetries", r.maxRetries,
 				"error", err)
 
 			if err := r.backoff(ctx, attempt, url, err, nil); err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			continue
 		}
-		return nil, err
+		return nil, nil, err
 	}
-	return nil, fmt.Errorf("max retries reached: last error: %w", err)
+	r.logGiveUp(ctx, url, err)
+	return nil, nil, fmt.Errorf("max retries reached: last error: %w", err)
 }
 
-func (r *RetryRequest) attemptFetchContents(ctx context.Context, url string) ([]byte, error) {
+func (r *RetryRequest) attemptFetchContents(ctx context.Context, url string) ([]byte, *http.Response, error) {
 	resp, cancel, err := r.GetResponse(ctx, url)
 	if cancel != nil {
 		defer cancel()
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get a response for the URL %s: %w", url, err)
+		return nil, nil, fmt.Errorf("failed to get a response for the URL %s: %w", url, err)
 	}
 	if resp == nil {
-		return nil, fmt.Errorf("failed to get a response (nil) for the URL %s", url)
+		return nil, nil, fmt.Errorf("failed to get a response (nil) for the URL %s", url)
 	}
 	defer func() {
 		if resp.Body != nil {
@@ -324,37 +674,33 @@ func (r *RetryRequest) attemptFetchContents(ctx context.Context, url string) ([]
 		}
 	}()
 
-	var reader io.Reader = resp.Body
-
-	// Handle gzip encoding if present
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, gzipReaderError := gzip.NewReader(resp.Body)
-		if gzipReaderError != nil {
-			slog.Error("Failed to create gzip reader", "err", gzipReaderError)
-			return nil, gzipReaderError
-		}
-		defer func() {
-			if gzipReader != nil {
-				if errLeak := gzipReader.Close(); errLeak != nil {
-					slog.Error("Failed to close gzip reader, potential leak", "err", errLeak)
-				}
-			}
-		}()
-		reader = gzipReader
+	reader, release, err := r.decodeBody(resp)
+	if err != nil {
+		return nil, nil, &PhaseError{Phase: ErrPhaseResponse, Err: fmt.Errorf("%w: %w", errReadingBody, err)}
 	}
+	defer release()
 
-	contentType := resp.Header.Get("Content-Type")
-	if strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "json") || strings.Contains(contentType, "xml") {
-		decodedReader, err := charset.NewReader(reader, contentType)
-		if err != nil {
-			slog.Error("Failed to decode response content", "err", err)
-			return nil, err
-		}
-		return io.ReadAll(decodedReader)
-	} else {
-		// For binary data, read raw bytes directly
-		return io.ReadAll(reader)
+	bodyBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, &PhaseError{Phase: ErrPhaseResponse, Err: fmt.Errorf("%w: %w", errReadingBody, err)}
 	}
+	return bodyBytes, resp, nil
+}
+
+// FetchResult is the decoded body of a successful GET together with the response metadata worth keeping alongside it.
+type FetchResult struct {
+	Body       []byte
+	StatusCode int
+	Header     http.Header
+	FinalURL   string // URL the content was finally served from, after redirects
+	FetchedAt  time.Time
+}
+
+// Fetch sends an HTTP GET request to the specified URL, handling gzip encoding and charset decoding, and returns the
+// body together with the status code, response headers (e.g. Content-Type, Last-Modified, ETag) and final URL.
+// It is the primitive the GetContents family is built on.
+func (r *RetryRequest) Fetch(ctx context.Context, url string) (*FetchResult, error) {
+	return r.fetch(ctx, url)
 }
 
 // GetContents sends an HTTP GET request to retrieve content from the specified URL, handling gzip encoding if present.
@@ -366,6 +712,28 @@ func (r *RetryRequest) GetContents(url string) (string, error) {
 	return string(bodyBytes), nil
 }
 
+// GetContentsWithContext sends an HTTP GET request to retrieve content from the specified URL, handling gzip encoding
+// if present. The request context is created, used and released internally.
+func (r *RetryRequest) GetContentsWithContext(ctx context.Context, url string) (string, error) {
+	bodyBytes, err := r.fetchContentsAsBytes(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	return string(bodyBytes), nil
+}
+
+// GetJSON sends an HTTP GET request to the specified URL and decodes the JSON response into v.
+func (r *RetryRequest) GetJSON(ctx context.Context, url string, v interface{}) error {
+	bodyBytes, err := r.fetchContentsAsBytes(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bodyBytes, v); err != nil {
+		return fmt.Errorf("failed to decode JSON response from %s: %w", url, err)
+	}
+	return nil
+}
+
 // GetContentsAsBytes sends an HTTP GET request to retrieve content from the specified URL, handling gzip encoding if present.
 func (r *RetryRequest) GetContentsAsBytes(url string) ([]byte, error) {
 	bodyBytes, err := r.fetchContentsAsBytes(context.Background(), url)
@@ -397,84 +765,153 @@ func (r *RetryRequest) GetContentFromURL(url *url.URL) ([]byte, error) {
 
 // PostContentsAsBytes sends an HTTP Post request to retrieve content from the specified URL, handling gzip encoding if present.
 func (r *RetryRequest) PostContentsAsBytes(url string, reader io.Reader) ([]byte, error) {
-	bodyBytes, err := r.fetchContentsAsBytesPost(url, reader)
+	bodyBytes, err := r.fetchContentsAsBytesPost(context.Background(), url, reader)
 	if err != nil {
 		return nil, err
 	}
 	return bodyBytes, nil
 }
 
-// GetCSV sends an HTTP GET request to retrieve CSV content from the specified URL.
-func (r *RetryRequest) GetCSV(url string) (string, error) {
-	resp, cancel, err := r.GetResponse(context.Background(), url)
-	defer cancel()
-	if err != nil || resp == nil {
-		return "", fmt.Errorf("failed to get a csv response for the URL: %w", err)
+// PostContentsAsBytesWithContext sends an HTTP Post request to retrieve content from the specified URL, handling gzip
+// encoding if present. The request context is created, used and released internally.
+func (r *RetryRequest) PostContentsAsBytesWithContext(ctx context.Context, url string, reader io.Reader) ([]byte, error) {
+	bodyBytes, err := r.fetchContentsAsBytesPost(ctx, url, reader)
+	if err != nil {
+		return nil, err
 	}
-	defer func(Body io.ReadCloser) {
-		closeErr := Body.Close()
-		if closeErr != nil {
-			slog.Error("Failed to close response body", "err", closeErr)
-		}
-	}(resp.Body)
+	return bodyBytes, nil
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+// GetCSV sends an HTTP GET request to retrieve CSV content from the specified URL, handling gzip encoding if present.
+func (r *RetryRequest) GetCSV(url string) (string, error) {
+	return r.GetCSVWithContext(context.Background(), url)
+}
+
+// GetCSVWithContext sends an HTTP GET request to retrieve CSV content from the specified URL, handling gzip encoding
+// and charset decoding the same way as GetContents.
+func (r *RetryRequest) GetCSVWithContext(ctx context.Context, url string) (string, error) {
+	bodyBytes, err := r.fetchContentsAsBytes(ctx, url)
 	if err != nil {
-		slog.Error("Failed to read response content", "err", err)
-		return "", err
+		return "", fmt.Errorf("failed to get a csv response for the URL: %w", err)
 	}
-
 	return string(bodyBytes), nil
 }
 
 // SendPostRequest sends an HTTP POST request to the specified URL with retries on failures.
-// The body parameter is the data to be sent in the POST request.
+// The body parameter is the data to be sent in the POST request. It is read in full before the first attempt, so that
+// every retry resends the same body. As with GetResponse, resp.Body is the raw, possibly compressed, response body.
 func (r *RetryRequest) SendPostRequest(url string, body io.Reader) (*http.Response, context.CancelFunc, error) {
-	if r.isRateLimited {
-		err := r.limiter.Wait(context.Background())
+	return r.sendPostRequest(context.Background(), url, body)
+}
+
+// SendPostRequestWithContext is like SendPostRequest but runs under ctx, which also bounds the wait for the rate
+// limiter and a concurrency slot.
+func (r *RetryRequest) SendPostRequestWithContext(ctx context.Context, url string, body io.Reader) (*http.Response, context.CancelFunc, error) {
+	return r.sendPostRequest(ctx, url, body)
+}
+
+func (r *RetryRequest) sendPostRequest(ctx context.Context, url string, body io.Reader) (*http.Response, context.CancelFunc, error) {
+	return r.withConcurrencySlot(ctx, func() (*http.Response, context.CancelFunc, error) {
+		return r.sendPostRequestWithRetries(ctx, url, body)
+	})
+}
+
+func (r *RetryRequest) sendPostRequestWithRetries(ctx context.Context, url string, body io.Reader) (*http.Response, context.CancelFunc, error) {
+	if err := validateURL(url); err != nil {
+		return nil, nil, &PhaseError{Phase: ErrPhaseSetup, Err: err}
+	}
+
+	ctx, tracker := withPhaseTracker(ctx)
+	resp, cancel, err := r.postWithRetries(ctx, url, body)
+	return resp, cancel, tracker.wrap(err)
+}
+
+func (r *RetryRequest) postWithRetries(ctx context.Context, url string, body io.Reader) (*http.Response, context.CancelFunc, error) {
+	ctx = r.withRequestID(ctx)
+
+	if r.isRateLimited && r.dryRun == nil {
+		err := r.limiter.Wait(ctx)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 
+	// A reader can only be sent once, so the body is buffered for the retries
+	var payload []byte
+	if body != nil {
+		var readErr error
+		if payload, readErr = io.ReadAll(body); readErr != nil {
+			return nil, nil, fmt.Errorf("error reading POST body: %w", readErr)
+		}
+	}
+
+	var idempotencyKey string
+	if r.idempotencyKeyFunc != nil {
+		idempotencyKey = r.idempotencyKeyFunc()
+	}
+
 	var resp *http.Response
 	var err error
 
 	for i := 0; i < r.maxRetries; i++ {
-		ctx, cancel := context.WithTimeout(context.Background(), r.requestTimeout)
-		req, reqErr := http.NewRequestWithContext(ctx, "POST", url, body)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, r.requestTimeout)
+		req, reqErr := http.NewRequestWithContext(attemptCtx, "POST", url, bytes.NewReader(payload))
 		if reqErr != nil {
 			cancel()
 			return nil, nil, reqErr
 		}
 
-		req.Header = r.headers
+		header, headerErr := r.requestHeaders(attemptCtx)
+		if headerErr != nil {
+			cancel()
+			return nil, nil, headerErr
+		}
+		if idempotencyKey != "" {
+			header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		req.Header = header
 		resp, err = r.client.Do(req)
+		recordPhase(attemptCtx, resp, err)
 		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
 			// Successful request
+			r.recordAttempt(true, url, resp, nil)
 			return resp, cancel, nil
 		}
+		if err == nil && !r.isRetryableStatus(resp.StatusCode) {
+			err := statusNotRetried(resp, url)
+			cancel()
+			return nil, nil, err
+		}
+		if resp != nil {
+			drainAndCloseBody(resp.Body)
+		}
 		cancel()
 
-		if resp != nil {
-			if closeErr := resp.Body.Close(); closeErr != nil {
-				slog.Error("Failed to close response body, potential leak", "error", closeErr)
-			}
+		if budgetErr := r.recordAttempt(false, url, resp, err); budgetErr != nil && i < r.maxRetries-1 {
+			return nil, nil, budgetErr
 		}
 
-		// Delay for exponential backoff
-		time.Sleep(r.backoffFactor * time.Duration(1<<i))
-		slog.Info("Retrying POST request", "url", url, "attempt", i+1, "maxRetries", r.maxRetries)
+		// Wait for exponential backoff unless that was the last attempt
+		if i < r.maxRetries-1 {
+			if backoffErr := r.backoff(ctx, i, url, err, resp); backoffErr != nil {
+				return nil, nil, backoffErr
+			}
+		}
 	}
 
 	// If reached here, all retries failed
+	r.logGiveUp(ctx, url, err)
 	return nil, nil, fmt.Errorf("failed after max retries: last error: %w", err)
 }
 
-// fetchContentsAsBytes sends an HTTP GET request to retrieve content from the specified URL,
+// fetchContentsAsBytesPost sends an HTTP POST request to the specified URL,
 // handling gzip encoding if present, and returns content as bytes.
-func (r *RetryRequest) fetchContentsAsBytesPost(url string, body io.Reader) ([]byte, error) {
-	resp, cancel, err := r.SendPostRequest(url, body)
+func (r *RetryRequest) fetchContentsAsBytesPost(ctx context.Context, url string, body io.Reader) ([]byte, error) {
+	resp, cancel, err := r.sendPostRequest(ctx, url, body)
 	if cancel != nil {
 		defer cancel()
 	}
@@ -492,53 +929,26 @@ func (r *RetryRequest) fetchContentsAsBytesPost(url string, body io.Reader) ([]b
 		}
 	}()
 
-	var reader io.Reader = resp.Body
-	var bodyBytes []byte
-
-	// Handle gzip encoding if present
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, gzipReaderError := gzip.NewReader(resp.Body)
-		if gzipReaderError != nil {
-			slog.Error("Failed to create gzip reader", "err", err)
-			return nil, gzipReaderError
-		}
-		defer func() {
-			if gzipReader != nil {
-				if errLeak := gzipReader.Close(); errLeak != nil {
-					slog.Error("Failed to close gzip reader, potential leak", "err", errLeak)
-				}
-			}
-		}()
-		reader = gzipReader
+	reader, release, err := r.decodeBody(resp)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	contentType := resp.Header.Get("Content-Type")
-	if strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "json") || strings.Contains(contentType, "xml") {
-		decodedReader, err := charset.NewReader(reader, contentType)
-		if err != nil {
-			slog.Error("Failed to decode response content", "err", err)
-			return nil, err
-		}
-		bodyBytes, err = io.ReadAll(decodedReader)
-		if err != nil {
-			slog.Error("Failed to read response content", "err", err)
-			return nil, err
-		}
-	} else {
-		// For binary data, read raw bytes directly
-		bodyBytes, err = io.ReadAll(reader)
-		if err != nil {
-			slog.Error("Failed to read response content", "err", err)
-			return nil, err
-		}
+	bodyBytes, err := io.ReadAll(reader)
+	if err != nil {
+		slog.Error("Failed to read response content", "err", err)
+		return nil, err
 	}
 
 	return bodyBytes, nil
 }
 
-// GetContentsAsReader sends an HTTP GET request to retrieve content from the specified URL and returns an io.Reader
+// GetContentsAsReader sends an HTTP GET request to retrieve content from the specified URL and returns an
+// io.ReadCloser over the decoded body. The caller must Close it; closing releases the request context and the
+// underlying response body.
 // Note: In the future, we will want to have this return the content size from the response
-func (r *RetryRequest) GetContentsAsReader(url string) (io.Reader, error) {
+func (r *RetryRequest) GetContentsAsReader(url string) (io.ReadCloser, error) {
 	reader, err := r.fetchContentsAsReader(url)
 	if err != nil {
 		return nil, err
@@ -546,37 +956,95 @@ func (r *RetryRequest) GetContentsAsReader(url string) (io.Reader, error) {
 	return reader, nil
 }
 
-func (r *RetryRequest) fetchContentsAsReader(url string) (io.Reader, error) {
-	resp, _, err := r.GetResponse(context.Background(), url)
+// responseReadCloser ties the lifetime of a decoded response body to its request context.
+type responseReadCloser struct {
+	io.Reader
+	body    io.ReadCloser
+	release func()
+	cancel  context.CancelFunc
+}
+
+// Close releases any decoder, closes the response body and cancels the request context.
+func (rc *responseReadCloser) Close() error {
+	rc.release()
+	err := rc.body.Close()
+	rc.cancel()
+	return err
+}
+
+func (r *RetryRequest) fetchContentsAsReader(url string) (io.ReadCloser, error) {
+	resp, cancel, err := r.GetResponse(context.Background(), url)
 	if err != nil {
+		if cancel != nil {
+			cancel()
+		}
 		return nil, fmt.Errorf("failed to get a response for the URL %s: %w", url, err)
 	}
 	if resp == nil {
+		cancel()
 		return nil, fmt.Errorf("failed to get a response (nil) for the URL %s", url)
 	}
 
-	var reader io.Reader = resp.Body
+	reader, release, err := r.decodeBody(resp)
+	if err != nil {
+		closeResponseBody(resp.Body)
+		cancel()
+		return nil, err
+	}
 
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, gzipReaderError := gzip.NewReader(resp.Body)
-		if gzipReaderError != nil {
-			slog.Error("Failed to create gzip reader", "err", gzipReaderError)
-			return nil, gzipReaderError
-		}
-		reader = gzipReader
+	return &responseReadCloser{
+		Reader:  reader,
+		body:    resp.Body,
+		release: release,
+		cancel:  cancel,
+	}, nil
+}
+
+// maxDrainBytes caps how much of an unwanted response body is read before closing it. Bodies that are fully read can
+// have their connection reused by the Transport; larger bodies are cheaper to abandon than to drain.
+const maxDrainBytes = 64 * 1024
+
+// drainAndCloseBody discards up to maxDrainBytes of body and closes it, so the underlying keep-alive connection can be
+// reused for the next attempt instead of a fresh one being dialled.
+func drainAndCloseBody(body io.ReadCloser) {
+	if _, err := io.CopyN(io.Discard, body, maxDrainBytes); err != nil && !errors.Is(err, io.EOF) {
+		slog.Debug("Failed to drain response body", "err", err)
+	}
+	if err := body.Close(); err != nil {
+		slog.Error("Failed to close response body, potential leak, continuing", "err", err)
+	}
+}
+
+// decodeBody wraps the response body with decompression and charset decoding based on the response headers. A byte
+// order mark takes precedence over the charset, then WithForcedCharset, then the declared Content-Type charset. The
+// returned release func closes the decompressing reader, if one was created; closing resp.Body remains the caller's
+// responsibility.
+func (r *RetryRequest) decodeBody(resp *http.Response) (io.Reader, func(), error) {
+	reader, release, err := decompressBody(resp)
+	if err != nil {
+		return nil, release, err
 	}
 
 	contentType := resp.Header.Get("Content-Type")
 	if strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "json") || strings.Contains(contentType, "xml") {
+		if r.forcedCharset != "" {
+			if enc, _ := charset.Lookup(r.forcedCharset); enc == nil {
+				release()
+				return nil, func() {}, fmt.Errorf("unknown forced charset %q", r.forcedCharset)
+			}
+			// charset.NewReader checks for a BOM before the declared charset, so the BOM still wins
+			contentType = "text/plain; charset=" + r.forcedCharset
+		}
 		decodedReader, err := charset.NewReader(reader, contentType)
 		if err != nil {
 			slog.Error("Failed to decode response content", "err", err)
-			return nil, err
+			release()
+			return nil, func() {}, err
 		}
 		reader = decodedReader
 	}
 
-	return reader, nil
+	return reader, release, nil
 }
 
 func (r *RetryRequest) backoff(
@@ -586,6 +1054,10 @@ func (r *RetryRequest) backoff(
 	lastError error,
 	resp *http.Response) error {
 
+	if r.dryRun != nil {
+		return nil
+	}
+
 	backoffDuration := r.backoffFactor * time.Duration(1<<attempt)
 
 	logMessage := "Retrying request after backoff"
@@ -596,96 +1068,25 @@ func (r *RetryRequest) backoff(
 	}
 
 	// Log before waiting
+	logArgs := []any{
+		"url", url,
+		"attempt", attempt + 1,
+		"maxRetries", r.maxRetries,
+		"backoffDuration", backoffDuration,
+		"lastError", lastError,
+	}
 	if resp != nil {
-		slog.Info(logMessage,
-			"url", url,
-			"attempt", attempt+1,
-			"maxRetries", r.maxRetries,
-			"backoffDuration", backoffDuration,
-			"lastError", lastError,
+		logArgs = append(logArgs,
 			"responseStatusCode", resp.StatusCode,
 			"responseStatus", resp.Status,
 			"responseHeader", resp.Header)
-	} else {
-		slog.Info(logMessage,
-			"url", url,
-			"attempt", attempt+1,
-			"maxRetries", r.maxRetries,
-			"backoffDuration", backoffDuration,
-			"lastError", lastError)
 	}
-
-	timer := time.NewTimer(backoffDuration)
-	defer timer.Stop()
+	r.logRetry(ctx, logMessage, logArgs...)
 
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-timer.C:
+	case <-r.clock.After(backoffDuration):
 		return nil
 	}
 }
-
-// fetchContentsAsBytes sends an HTTP GET request to retrieve content from the specified URL,
-// handling gzip encoding if present, and returns content as bytes.
-func (r *RetryRequest) fetchContentsAsBytesV1(ctx context.Context, url string) ([]byte, error) {
-	resp, cancel, err := r.GetResponse(ctx, url)
-	if cancel != nil {
-		defer cancel()
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get a response for the URL %s: %w", url, err)
-	}
-	if resp == nil {
-		return nil, fmt.Errorf("failed to get a response (nil) for the URL %s", url)
-	}
-	defer func() {
-		if resp.Body != nil {
-			if closeErr := resp.Body.Close(); closeErr != nil {
-				slog.Error("Failed to close response body", "err", closeErr)
-			}
-		}
-	}()
-
-	var reader io.Reader = resp.Body
-	var bodyBytes []byte
-
-	// Handle gzip encoding if present
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, gzipReaderError := gzip.NewReader(resp.Body)
-		if gzipReaderError != nil {
-			slog.Error("Failed to create gzip reader", "err", gzipReaderError)
-			return nil, gzipReaderError
-		}
-		defer func() {
-			if gzipReader != nil {
-				if errLeak := gzipReader.Close(); errLeak != nil {
-					slog.Error("Failed to close gzip reader, potential leak", "err", errLeak)
-				}
-			}
-		}()
-		reader = gzipReader
-	}
-
-	contentType := resp.Header.Get("Content-Type")
-	if strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "json") || strings.Contains(contentType, "xml") {
-		decodedReader, err := charset.NewReader(reader, contentType)
-		if err != nil {
-			slog.Error("Failed to decode response content", "err", err)
-			return nil, err
-		}
-		bodyBytes, err = io.ReadAll(decodedReader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response content: %w", err)
-		}
-	} else {
-		// For binary data, read raw bytes directly
-		bodyBytes, err = io.ReadAll(reader)
-		if err != nil {
-			slog.Error("Failed to read response content", "err", err)
-			return nil, err
-		}
-	}
-
-	return bodyBytes, nil
-}
@@ -0,0 +1,263 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BenchmarkRetryConnectionReuse retries against a server that always answers 500 with a small body, and reports how
+// many TCP connections were opened per GetResponse call. With the retry path draining bodies before closing them,
+// every attempt of a call reuses the same keep-alive connection.
+func BenchmarkRetryConnectionReuse(b *testing.B) {
+	var newConns atomic.Int64
+	body := strings.Repeat("x", 4096)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(body))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(3, 0))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = r.GetResponse(context.Background(), server.URL)
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(newConns.Load())/float64(b.N), "conns/op")
+}
+
+func TestRequestIDSharedAcrossRetries(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ids = append(ids, req.Header.Get("X-Request-ID"))
+		if len(ids)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var calls atomic.Int64
+	r := NewRetryRequest(
+		WithAttemptsAndBackoff(3, 0),
+		WithRequestID("X-Request-ID", func() string { return fmt.Sprintf("id-%d", calls.Add(1)) }),
+	)
+	for i := 0; i < 2; i++ {
+		if _, err := r.GetContentsAsBytesWithContext(context.Background(), server.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"id-1", "id-1", "id-1", "id-2", "id-2", "id-2"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("request IDs = %v, want %v", ids, want)
+	}
+}
+
+func TestFetchContentsStopsRetryingOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Promise more than is sent, so reading the body fails with a transient unexpected EOF
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", "1024")
+		_, _ = w.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(5, 0))
+	// Shutdown is requested as the transient read error surfaces, i.e. between two attempts
+	transport := &cancellingTransport{RoundTripper: http.DefaultTransport, cancel: cancel}
+	r.client.Transport = transport
+
+	start := time.Now()
+	_, err := r.GetContentsAsBytesWithContext(ctx, server.URL)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v, want promptly", elapsed)
+	}
+	if n := transport.attempts.Load(); n != 1 {
+		t.Errorf("attempts = %d, want 1", n)
+	}
+}
+
+// cancellingTransport counts the requests it sends and calls cancel when reading a response body fails
+type cancellingTransport struct {
+	http.RoundTripper
+	cancel   context.CancelFunc
+	attempts atomic.Int64
+}
+
+func (t *cancellingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.attempts.Add(1)
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &cancellingBody{ReadCloser: resp.Body, cancel: t.cancel}
+	return resp, nil
+}
+
+type cancellingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancellingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.cancel()
+	}
+	return n, err
+}
+
+func TestUnsupportedURL(t *testing.T) {
+	r := NewRetryRequest(WithAttemptsAndBackoff(3, 0))
+	urls := []string{"file:///etc/passwd", "ftp://example.com/file", "https://", "example.com/page", "http://%zz"}
+	for _, url := range urls {
+		if _, err := r.GetContentsAsBytesWithContext(context.Background(), url); !errors.Is(err, ErrUnsupportedURL) {
+			t.Errorf("GET %q: err = %v, want ErrUnsupportedURL", url, err)
+		}
+		_, err := r.PostContentsAsBytesWithContext(context.Background(), url, strings.NewReader("{}"))
+		if !errors.Is(err, ErrUnsupportedURL) {
+			t.Errorf("POST %q: err = %v, want ErrUnsupportedURL", url, err)
+		}
+	}
+}
+
+func TestCancelAbortsRateLimiterWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Upload-Offset", "0")
+	}))
+	defer server.Close()
+
+	calls := map[string]func(r *RetryRequest, ctx context.Context) error{
+		"GET": func(r *RetryRequest, ctx context.Context) error {
+			_, err := r.GetContentsAsBytesWithContext(ctx, server.URL)
+			return err
+		},
+		"POST": func(r *RetryRequest, ctx context.Context) error {
+			_, _, err := r.SendPostRequestWithContext(ctx, server.URL, strings.NewReader("{}"))
+			return err
+		},
+		"upload": func(r *RetryRequest, ctx context.Context) error {
+			return r.UploadResumable(ctx, server.URL, strings.NewReader("x"), 1, 1)
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			// The burst is used up by a first request, so the second waits an hour for the limiter
+			r := NewRetryRequest(WithAttemptsAndBackoff(1, 0), WithRateLimiting(rate.Every(time.Hour), 1))
+			if !r.limiter.Allow() {
+				t.Fatal("expected the burst to be available")
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := call(r, ctx)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("err = %v, want context.Canceled", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("returned after %v, want promptly after the cancel", elapsed)
+			}
+		})
+	}
+}
+
+func TestCancelAbortsBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	calls := map[string]func(r *RetryRequest, ctx context.Context) error{
+		"GET": func(r *RetryRequest, ctx context.Context) error {
+			_, err := r.GetContentsAsBytesWithContext(ctx, server.URL)
+			return err
+		},
+		"POST": func(r *RetryRequest, ctx context.Context) error {
+			_, _, err := r.SendPostRequestWithContext(ctx, server.URL, strings.NewReader("{}"))
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			// The first attempt fails, so the request waits an hour before the second
+			r := NewRetryRequest(WithAttemptsAndBackoff(2, time.Hour))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := call(r, ctx)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("err = %v, want context.Canceled", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("returned after %v, want promptly after the cancel", elapsed)
+			}
+		})
+	}
+}
+
+func TestFollowMetaRefresh(t *testing.T) {
+	pages := map[string]string{
+		"/docs/start": `<meta http-equiv="refresh" content="0;URL=next">`,
+		"/docs/next":  `<script>location.replace("/final")</script>`,
+		"/final":      `<p>final page</p>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(pages[req.URL.Path]))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		options []RetryRequestOption
+		want    string
+	}{
+		{"not followed by default", nil, "/docs/start"},
+		{"stops after max hops", []RetryRequestOption{WithFollowMetaRefresh(1)}, "/docs/next"},
+		{"followed to the final page", []RetryRequestOption{WithFollowMetaRefresh(5)}, "/final"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRetryRequest(append([]RetryRequestOption{WithAttemptsAndBackoff(1, 0)}, tt.options...)...)
+			result, err := r.Fetch(context.Background(), server.URL+"/docs/start")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.FinalURL != server.URL+tt.want || string(result.Body) != pages[tt.want] {
+				t.Errorf("got %s with body %q, want %s", result.FinalURL, result.Body, tt.want)
+			}
+		})
+	}
+}
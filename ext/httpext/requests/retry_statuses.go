@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrStatusNotRetried is returned, wrapping a *StatusCodeError, when a response has a status that
+// WithRetryableStatuses or WithNonRetryableStatuses exclude from retrying.
+var ErrStatusNotRetried = errors.New("status not retried")
+
+// WithRetryableStatuses limits retrying, in GetResponse and the POST methods, to responses with one of the given
+// statuses: a response with any other unsuccessful status is returned at once as an error wrapping
+// ErrStatusNotRetried. By default every unsuccessful status is retried. Network errors are retried either way.
+func WithRetryableStatuses(codes ...int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.retryableStatuses = statusSet(codes)
+	}
+}
+
+// WithNonRetryableStatuses excludes responses with the given statuses from retrying, in GetResponse and the POST
+// methods: they are returned at once as an error wrapping ErrStatusNotRetried, e.g. for a 409 Conflict that will not
+// resolve itself. It takes precedence over WithRetryableStatuses, and repeated uses add to the excluded statuses.
+func WithNonRetryableStatuses(codes ...int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		if r.nonRetryableStatuses == nil {
+			r.nonRetryableStatuses = make(map[int]bool, len(codes))
+		}
+		for _, code := range codes {
+			r.nonRetryableStatuses[code] = true
+		}
+	}
+}
+
+// isRetryableStatus reports whether an unsuccessful response with statusCode may be retried
+func (r *RetryRequest) isRetryableStatus(statusCode int) bool {
+	if r.nonRetryableStatuses[statusCode] {
+		return false
+	}
+	return r.retryableStatuses == nil || r.retryableStatuses[statusCode]
+}
+
+// statusNotRetried closes the body of resp, which is not retried, and returns the error reporting it
+func statusNotRetried(resp *http.Response, url string) error {
+	drainAndCloseBody(resp.Body)
+	return fmt.Errorf("%w: %w", ErrStatusNotRetried, &StatusCodeError{
+		StatusCode: resp.StatusCode,
+		URL:        url,
+		Message:    resp.Status,
+	})
+}
+
+func statusSet(codes []int) map[int]bool {
+	set := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}
@@ -0,0 +1,76 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRetryStatuses(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	conflict, unavailable := http.StatusConflict, http.StatusServiceUnavailable
+	tests := []struct {
+		name         string
+		options      []RetryRequestOption
+		status       int
+		post         bool
+		wantAttempts int32
+	}{
+		{"default retries every status", nil, conflict, false, 3},
+		{"non-retryable", []RetryRequestOption{WithNonRetryableStatuses(conflict)}, conflict, false, 1},
+		{"non-retryable POST", []RetryRequestOption{WithNonRetryableStatuses(conflict)}, conflict, true, 1},
+		{"retryable", []RetryRequestOption{WithRetryableStatuses(unavailable)}, unavailable, false, 3},
+		{"not in retryable", []RetryRequestOption{WithRetryableStatuses(unavailable)}, conflict, true, 1},
+		{"non-retryable takes precedence", []RetryRequestOption{
+			WithRetryableStatuses(unavailable),
+			WithNonRetryableStatuses(unavailable),
+		}, unavailable, false, 1},
+		{"WithNoRetry422", []RetryRequestOption{WithNoRetry422()}, http.StatusUnprocessableEntity, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts.Store(0)
+			r := NewRetryRequest(append([]RetryRequestOption{WithAttemptsAndBackoff(3, 0)}, tt.options...)...)
+			url := server.URL + "/" + strconv.Itoa(tt.status)
+
+			var resp *http.Response
+			var cancel context.CancelFunc
+			var err error
+			if tt.post {
+				resp, cancel, err = r.SendPostRequestWithContext(context.Background(), url, strings.NewReader("{}"))
+			} else {
+				resp, cancel, err = r.GetResponse(context.Background(), url)
+			}
+			if cancel != nil {
+				cancel()
+			}
+			if resp != nil || err == nil {
+				t.Fatalf("got response %v, error %v; want only an error", resp, err)
+			}
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if notRetried := errors.Is(err, ErrStatusNotRetried); notRetried != (tt.wantAttempts == 1) {
+				t.Errorf("errors.Is(%v, ErrStatusNotRetried) = %v", err, notRetried)
+			}
+			var statusErr *StatusCodeError
+			if tt.wantAttempts == 1 && (!errors.As(err, &statusErr) || statusErr.StatusCode != tt.status) {
+				t.Errorf("err = %v, want a StatusCodeError with status %d", err, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package sec provides typed access to the SEC EDGAR JSON APIs, built on the rate limited requests.SECRequest.
+package sec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"vmuser/ext/httpext/requests"
+)
+
+// EdgarDataBaseURL is the host serving the EDGAR JSON APIs.
+const EdgarDataBaseURL = "https://data.sec.gov"
+
+// cikLength is the number of digits of a CIK as it appears in EDGAR URLs.
+const cikLength = 10
+
+// ErrInvalidCIK is returned when a CIK is empty, non-numeric or longer than 10 digits.
+var ErrInvalidCIK = errors.New("invalid CIK")
+
+// GetSubmissions retrieves the filing history and company information for the company with the given CIK.
+func GetSubmissions(ctx context.Context, cik string) (*Submissions, error) {
+	if err := ValidateCIK(cik); err != nil {
+		return nil, err
+	}
+
+	var submissions Submissions
+	if err := requests.NewSECRequest().GetJSON(ctx, SubmissionsURL(cik), &submissions); err != nil {
+		return nil, fmt.Errorf("error getting submissions for CIK %s: %w", cik, err)
+	}
+	return &submissions, nil
+}
+
+// GetCompanyFacts retrieves every XBRL fact reported by the company with the given CIK.
+func GetCompanyFacts(ctx context.Context, cik string) (*CompanyFacts, error) {
+	if err := ValidateCIK(cik); err != nil {
+		return nil, err
+	}
+
+	var facts CompanyFacts
+	if err := requests.NewSECRequest().GetJSON(ctx, CompanyFactsURL(cik), &facts); err != nil {
+		return nil, fmt.Errorf("error getting company facts for CIK %s: %w", cik, err)
+	}
+	return &facts, nil
+}
+
+// ValidateCIK reports whether cik is a usable CIK: 1 to 10 decimal digits, ignoring surrounding whitespace. The
+// returned error wraps ErrInvalidCIK.
+func ValidateCIK(cik string) error {
+	cik = strings.TrimSpace(cik)
+	if cik == "" || len(cik) > cikLength {
+		return fmt.Errorf("%w: %q", ErrInvalidCIK, cik)
+	}
+	for _, c := range cik {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("%w: %q", ErrInvalidCIK, cik)
+		}
+	}
+	return nil
+}
+
+// PadCIK left-pads a CIK with zeros to the 10 digits used in EDGAR URLs, e.g. "320193" becomes "0000320193". It does
+// not validate its input; use ValidateCIK first when the CIK comes from user input.
+func PadCIK(cik string) string {
+	cik = strings.TrimSpace(cik)
+	if len(cik) >= cikLength {
+		return cik
+	}
+	return strings.Repeat("0", cikLength-len(cik)) + cik
+}
+
+// SubmissionsURL returns the EDGAR submissions API URL for cik.
+func SubmissionsURL(cik string) string {
+	return fmt.Sprintf("%s/submissions/CIK%s.json", EdgarDataBaseURL, PadCIK(cik))
+}
+
+// CompanyFactsURL returns the EDGAR company facts API URL for cik.
+func CompanyFactsURL(cik string) string {
+	return fmt.Sprintf("%s/api/xbrl/companyfacts/CIK%s.json", EdgarDataBaseURL, PadCIK(cik))
+}
@@ -0,0 +1,44 @@
+package sec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateCIK(t *testing.T) {
+	tests := []struct {
+		cik     string
+		wantErr bool
+	}{
+		{"320193", false},
+		{"0000320193", false},
+		{" 320193 ", false},
+		{"", true},
+		{"CIK0000320193", true},
+		{"32019a", true},
+		{"-320193", true},
+		{"00000320193", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateCIK(tt.cik)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ValidateCIK(%q) = %v, wantErr %v", tt.cik, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidCIK) {
+			t.Fatalf("ValidateCIK(%q) = %v, want an ErrInvalidCIK", tt.cik, err)
+		}
+	}
+}
+
+func TestEdgarURLs(t *testing.T) {
+	if got, want := PadCIK("320193"), "0000320193"; got != want {
+		t.Fatalf("PadCIK = %q, want %q", got, want)
+	}
+	if got, want := SubmissionsURL("315189"), "https://data.sec.gov/submissions/CIK0000315189.json"; got != want {
+		t.Fatalf("SubmissionsURL = %q, want %q", got, want)
+	}
+	if got, want := CompanyFactsURL("0000315189"), "https://data.sec.gov/api/xbrl/companyfacts/CIK0000315189.json"; got != want {
+		t.Fatalf("CompanyFactsURL = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package sec
+
+// Submissions is the response of the EDGAR submissions API (https://data.sec.gov/submissions/CIK##########.json).
+type Submissions struct {
+	CIK                               string           `json:"cik"`
+	EntityType                        string           `json:"entityType"`
+	SIC                               string           `json:"sic"`
+	SICDescription                    string           `json:"sicDescription"`
+	InsiderTransactionForOwnerExists  int              `json:"insiderTransactionForOwnerExists"`
+	InsiderTransactionForIssuerExists int              `json:"insiderTransactionForIssuerExists"`
+	Name                              string           `json:"name"`
+	Tickers                           []string         `json:"tickers"`
+	Exchanges                         []string         `json:"exchanges"`
+	EIN                               string           `json:"ein"`
+	Description                       string           `json:"description"`
+	Website                           string           `json:"website"`
+	InvestorWebsite                   string           `json:"investorWebsite"`
+	Category                          string           `json:"category"`
+	FiscalYearEnd                     string           `json:"fiscalYearEnd"`
+	StateOfIncorporation              string           `json:"stateOfIncorporation"`
+	StateOfIncorporationDescription   string           `json:"stateOfIncorporationDescription"`
+	Addresses                         Addresses        `json:"addresses"`
+	Phone                             string           `json:"phone"`
+	Flags                             string           `json:"flags"`
+	FormerNames                       []FormerName     `json:"formerNames"`
+	Filings                           SubmissionsFiles `json:"filings"`
+}
+
+// Addresses holds a company's mailing and business addresses.
+type Addresses struct {
+	Mailing  Address `json:"mailing"`
+	Business Address `json:"business"`
+}
+
+// Address is a postal address as reported to EDGAR.
+type Address struct {
+	Street1                   string `json:"street1"`
+	Street2                   string `json:"street2"`
+	City                      string `json:"city"`
+	StateOrCountry            string `json:"stateOrCountry"`
+	ZipCode                   string `json:"zipCode"`
+	StateOrCountryDescription string `json:"stateOrCountryDescription"`
+}
+
+// FormerName is a name the company previously filed under, with the period it was in use.
+type FormerName struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SubmissionsFiles holds the most recent filings inline, plus references to the files holding older filings.
+type SubmissionsFiles struct {
+	Recent RecentFilings   `json:"recent"`
+	Files  []FilingHistory `json:"files"`
+}
+
+// RecentFilings holds recent filings column-wise: the i-th element of every slice describes the same filing.
+type RecentFilings struct {
+	AccessionNumber       []string `json:"accessionNumber"`
+	FilingDate            []string `json:"filingDate"`
+	ReportDate            []string `json:"reportDate"`
+	AcceptanceDateTime    []string `json:"acceptanceDateTime"`
+	Act                   []string `json:"act"`
+	Form                  []string `json:"form"`
+	FileNumber            []string `json:"fileNumber"`
+	FilmNumber            []string `json:"filmNumber"`
+	Items                 []string `json:"items"`
+	Size                  []int64  `json:"size"`
+	IsXBRL                []int    `json:"isXBRL"`
+	IsInlineXBRL          []int    `json:"isInlineXBRL"`
+	PrimaryDocument       []string `json:"primaryDocument"`
+	PrimaryDocDescription []string `json:"primaryDocDescription"`
+}
+
+// FilingHistory references an additional submissions file holding older filings.
+type FilingHistory struct {
+	Name        string `json:"name"`
+	FilingCount int    `json:"filingCount"`
+	FilingFrom  string `json:"filingFrom"`
+	FilingTo    string `json:"filingTo"`
+}
+
+// CompanyFacts is the response of the EDGAR company facts API
+// (https://data.sec.gov/api/xbrl/companyfacts/CIK##########.json).
+type CompanyFacts struct {
+	CIK        int64  `json:"cik"`
+	EntityName string `json:"entityName"`
+	// Facts maps a taxonomy (e.g. "us-gaap", "dei") to the concepts reported in it, keyed by concept name.
+	Facts map[string]map[string]Concept `json:"facts"`
+}
+
+// Concept is a single XBRL concept with every value reported for it, grouped by unit of measure (e.g. "USD").
+type Concept struct {
+	Label       string            `json:"label"`
+	Description string            `json:"description"`
+	Units       map[string][]Fact `json:"units"`
+}
+
+// Fact is a single reported value of a concept.
+type Fact struct {
+	Start string  `json:"start,omitempty"`
+	End   string  `json:"end"`
+	Val   float64 `json:"val"`
+	Accn  string  `json:"accn"`
+	FY    int     `json:"fy"`
+	FP    string  `json:"fp"`
+	Form  string  `json:"form"`
+	Filed string  `json:"filed"`
+	Frame string  `json:"frame,omitempty"`
+}
@@ -0,0 +1,81 @@
+package sec
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeSubmissions(t *testing.T) {
+	data := `{
+  "cik": "315189",
+  "entityType": "operating",
+  "name": "DEERE & CO",
+  "tickers": ["DE"],
+  "exchanges": ["NYSE"],
+  "fiscalYearEnd": "1029",
+  "stateOfIncorporation": "DE",
+  "addresses": {
+    "business": {"street1": "ONE JOHN DEERE PLACE", "city": "MOLINE", "stateOrCountry": "IL", "zipCode": "61265-8098"}
+  },
+  "formerNames": [{"name": "DEERE & CO", "from": "1994-01-01T00:00:00.000Z", "to": "2004-01-01T00:00:00.000Z"}],
+  "filings": {
+    "recent": {
+      "accessionNumber": ["0001558370-23-016561"],
+      "filingDate": ["2023-10-03"],
+      "form": ["8-K"],
+      "size": [215783],
+      "isXBRL": [1]
+    },
+    "files": [{"name": "CIK0000315189-submissions-001.json", "filingCount": 1967}]
+  }
+}`
+
+	var submissions Submissions
+	if err := json.Unmarshal([]byte(data), &submissions); err != nil {
+		t.Fatalf("Failed to unmarshal submissions: %v", err)
+	}
+
+	if want := "DEERE & CO"; submissions.Name != want {
+		t.Fatalf("Expected company name to be %q but got %q", want, submissions.Name)
+	}
+	if got := submissions.Addresses.Business.City; got != "MOLINE" {
+		t.Fatalf("Expected business city MOLINE but got %q", got)
+	}
+	if got := submissions.Filings.Recent.Form; len(got) != 1 || got[0] != "8-K" {
+		t.Fatalf("Expected recent forms [8-K] but got %v", got)
+	}
+	if got := submissions.Filings.Files; len(got) != 1 || got[0].FilingCount != 1967 {
+		t.Fatalf("Expected one older filings file with 1967 filings but got %+v", got)
+	}
+}
+
+func TestDecodeCompanyFacts(t *testing.T) {
+	data := `{
+  "cik": 315189,
+  "entityName": "DEERE & CO",
+  "facts": {
+    "dei": {
+      "EntityCommonStockSharesOutstanding": {
+        "label": "Entity Common Stock, Shares Outstanding",
+        "description": "Indicate number of shares outstanding.",
+        "units": {
+          "shares": [{"end": "2023-05-19", "val": 292473226, "accn": "0001558370-23-009883", "fy": 2023, "fp": "Q2", "form": "10-Q", "filed": "2023-05-26", "frame": "CY2023Q2I"}]
+        }
+      }
+    }
+  }
+}`
+
+	var facts CompanyFacts
+	if err := json.Unmarshal([]byte(data), &facts); err != nil {
+		t.Fatalf("Failed to unmarshal company facts: %v", err)
+	}
+
+	if want := "DEERE & CO"; facts.EntityName != want {
+		t.Fatalf("Expected entity name to be %q but got %q", want, facts.EntityName)
+	}
+	shares := facts.Facts["dei"]["EntityCommonStockSharesOutstanding"].Units["shares"]
+	if len(shares) != 1 || shares[0].Val != 292473226 || shares[0].FY != 2023 {
+		t.Fatalf("Unexpected shares outstanding facts: %+v", shares)
+	}
+}
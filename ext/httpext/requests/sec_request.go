@@ -5,7 +5,7 @@ package requests
 import (
 	"sync"
 	"time"
-	"xbrlgo/ext/httpext/headers"
+	"vmuser/ext/httpext/headers"
 )
 
 // SECRequest wraps the RetryRequest struct to provide specific configurations suitable for SEC-related requests.
@@ -32,6 +32,9 @@ const (
 // It initializes a singleton instance of the SECRequest struct if it hasn't already been initialized.
 // It sets specific headers for the SEC, sets the number of retry attempts, backoff delay, and rate limiting configurations.
 // As of July 27, 2021, the SEC limits automated searches to a total of no more than 10 requests per second.
+//
+// The singleton lives for the lifetime of the process and is shared by every caller; calling Close on it only drops
+// idle connections and leaves it usable.
 func NewSECRequest() *SECRequest {
 	once.Do(func() {
 		instance = &SECRequest{
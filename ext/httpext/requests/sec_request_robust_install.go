@@ -5,7 +5,7 @@ package requests
 import (
 	"sync"
 	"time"
-	"xbrlgo/ext/httpext/headers"
+	"vmuser/ext/httpext/headers"
 )
 
 // SECRequestInstallerRobuster wraps the RetryRequest struct to provide specific configurations suitable for
@@ -31,6 +31,8 @@ const (
 //
 // See SECRequest for more details. SECRequestInstallerRobuster is designed to be used in long-running installation
 // processes.
+//
+// Like NewSECRequest, the singleton lives for the lifetime of the process and is shared by every caller.
 func NewSECRequestInstallerRequest() *SECRequestInstallerRobuster {
 	onceSECInstaller.Do(func() {
 		instanceSECInstaller = &SECRequestInstallerRobuster{
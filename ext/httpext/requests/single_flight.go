@@ -0,0 +1,93 @@
+package requests
+
+import (
+	"context"
+	"golang.org/x/sync/singleflight"
+	"sync"
+)
+
+// WithSingleFlight coalesces concurrent identical GETs made through the GetContents family (keyed by method and URL)
+// so that only one network request is made and every caller receives its result, or its error. Nothing is cached:
+// a request made after the shared one completes goes to the network again.
+//
+// The shared request runs with the values of the caller that started it, but is only cancelled once every caller
+// waiting for it has stopped waiting, so a caller that gives up does not fail the others. Each caller stops waiting,
+// with its own context's error, if its context is done first.
+func WithSingleFlight() RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.singleFlight = &singleFlightGroup{calls: make(map[string]*sharedCall)}
+	}
+}
+
+// singleFlightGroup is a singleflight.Group that also counts the callers waiting for each shared request, so the
+// request can be cancelled when the last of them leaves
+type singleFlightGroup struct {
+	group singleflight.Group
+	mu    sync.Mutex
+	calls map[string]*sharedCall
+}
+
+// sharedCall is the context of a shared request and the number of callers waiting for it
+type sharedCall struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// join registers a caller waiting for the shared request for key, creating its context from ctx if there is none
+func (g *singleFlightGroup) join(ctx context.Context, key string) *sharedCall {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call, ok := g.calls[key]
+	if !ok {
+		sharedCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &sharedCall{ctx: sharedCtx, cancel: cancel}
+		g.calls[key] = call
+	}
+	call.waiters++
+	return call
+}
+
+// leave unregisters a caller of call. When the last caller leaves, the shared request is cancelled if still running
+// and forgotten, so a later caller starts a new one rather than joining the cancelled one.
+func (g *singleFlightGroup) leave(key string, call *sharedCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+		g.group.Forget(key)
+	}
+}
+
+func (r *RetryRequest) fetchSingleFlight(ctx context.Context, url string) (*FetchResult, error) {
+	key := "GET " + url
+	call := r.singleFlight.join(ctx, key)
+	defer r.singleFlight.leave(key, call)
+
+	ch := r.singleFlight.group.DoChan(key, func() (interface{}, error) {
+		return r.fetchUncoalesced(call.ctx, url)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		result := *res.Val.(*FetchResult)
+		if res.Shared {
+			// every caller gets its own copy so one cannot modify another's body or headers
+			result.Body = append([]byte(nil), result.Body...)
+			result.Header = result.Header.Clone()
+		}
+		return &result, nil
+	}
+}
@@ -0,0 +1,108 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlightSurvivesFirstCallerCancel(t *testing.T) {
+	var hits atomic.Int64
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Write([]byte("shared body"))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(1, 0), WithSingleFlight())
+	defer r.Close()
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := r.Fetch(firstCtx, server.URL)
+		firstErr <- err
+	}()
+	<-started
+
+	type result struct {
+		body string
+		err  error
+	}
+	second := make(chan result, 1)
+	go func() {
+		res, err := r.Fetch(context.Background(), server.URL)
+		if err != nil {
+			second <- result{err: err}
+			return
+		}
+		second <- result{body: string(res.Body)}
+	}()
+	// Give the second caller time to join the shared request before the first one gives up
+	time.Sleep(50 * time.Millisecond)
+
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first caller err = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	res := <-second
+	if res.err != nil {
+		t.Fatalf("second caller failed after the first was cancelled: %v", res.err)
+	}
+	if res.body != "shared body" {
+		t.Errorf("second caller body = %q, want %q", res.body, "shared body")
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server hit %d times, want 1", got)
+	}
+}
+
+func TestSingleFlightCancelledWhenAllCallersLeave(t *testing.T) {
+	var hits atomic.Int64
+	aborted := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			// The first request only ends when the client gives up on it
+			<-r.Context().Done()
+			aborted <- struct{}{}
+			return
+		}
+		w.Write([]byte("fresh body"))
+	}))
+	defer server.Close()
+
+	r := NewRetryRequest(WithAttemptsAndBackoff(1, 0), WithSingleFlight())
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := r.Fetch(ctx, server.URL); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("shared request kept running after its only caller left")
+	}
+
+	// A later caller starts a new request rather than joining the cancelled one
+	res, err := r.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Body) != "fresh body" {
+		t.Errorf("body = %q, want %q", res.Body, "fresh body")
+	}
+}
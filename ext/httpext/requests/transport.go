@@ -0,0 +1,64 @@
+package requests
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultDialKeepAlive matches the keep-alive period of http.DefaultTransport's dialer.
+const defaultDialKeepAlive = 30 * time.Second
+
+// WithDialTimeout bounds how long establishing a TCP connection (including DNS resolution) may take, independently of
+// the overall request timeout set with WithRequestTimeout. Unreachable hosts then fail fast while slow but reachable
+// ones can still use the full request budget. A zero duration means no dial timeout beyond the request's own.
+func WithDialTimeout(d time.Duration) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.transport().DialContext = (&net.Dialer{
+			Timeout:   d,
+			KeepAlive: defaultDialKeepAlive,
+		}).DialContext
+	}
+}
+
+// WithTLSHandshakeTimeout bounds how long the TLS handshake may take once connected, independently of the overall
+// request timeout set with WithRequestTimeout. A zero duration means no handshake timeout beyond the request's own.
+func WithTLSHandshakeTimeout(d time.Duration) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.transport().TLSHandshakeTimeout = d
+	}
+}
+
+// WithForceHTTP1 configures the client to only speak HTTP/1.1, never negotiating HTTP/2. This is useful to work around
+// hosts with broken HTTP/2 support (e.g. repeated stream errors).
+func WithForceHTTP1() RetryRequestOption {
+	return func(r *RetryRequest) {
+		t := r.transport()
+		t.ForceAttemptHTTP2 = false
+		// A non-nil, empty TLSNextProto disables HTTP/2
+		t.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper)
+	}
+}
+
+// WithForceHTTP2 configures the client to always attempt HTTP/2 over TLS, undoing WithForceHTTP1 and enabling HTTP/2
+// even when other transport options (such as a custom dialer) would otherwise turn it off. Plain http:// requests
+// still use HTTP/1.1.
+func WithForceHTTP2() RetryRequestOption {
+	return func(r *RetryRequest) {
+		t := r.transport()
+		t.ForceAttemptHTTP2 = true
+		t.TLSNextProto = nil
+	}
+}
+
+// transport returns the client's *http.Transport for options to configure, replacing the implicit default with a
+// clone of http.DefaultTransport the first time it is called.
+func (r *RetryRequest) transport() *http.Transport {
+	if t, ok := r.client.Transport.(*http.Transport); ok {
+		return t
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	r.client.Transport = t
+	return t
+}
@@ -0,0 +1,26 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrUnsupportedURL is returned before anything is sent when a URL cannot be fetched over HTTP: it does not parse, its
+// scheme is not http or https, or it has no host.
+var ErrUnsupportedURL = errors.New("unsupported URL")
+
+// validateURL checks that rawURL is an absolute http or https URL with a host
+func validateURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnsupportedURL, err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("%w %q: scheme must be http or https", ErrUnsupportedURL, rawURL)
+	}
+	if parsedURL.Hostname() == "" {
+		return fmt.Errorf("%w %q: no host", ErrUnsupportedURL, rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// redactedHeaders are never written to the wire log.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// WithWireLogging logs every outgoing request and incoming response at debug level, including headers and up to
+// maxBytes of each body, with credentials such as the Authorization header redacted. Bodies are re-buffered so the
+// request is still sent in full and the normal path still reads the whole response.
+func WithWireLogging(maxBytes int) RetryRequestOption {
+	return func(r *RetryRequest) {
+		r.wireLogMaxBytes = maxBytes
+	}
+}
+
+// wireLoggingTransport is an http.RoundTripper that logs what goes over the wire before delegating to next.
+type wireLoggingTransport struct {
+	next     http.RoundTripper
+	maxBytes int
+}
+
+func (t *wireLoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		closeErr := req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if closeErr != nil {
+			slog.Warn("Failed to close request body", "error", closeErr)
+		}
+		// RoundTrip must not modify the caller's request, so send a copy carrying the buffered body
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	slog.Debug("HTTP request",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"header", redactHeader(req.Header),
+		"body", truncateBody(reqBody, t.maxBytes))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		slog.Debug("HTTP request failed", "method", req.Method, "url", req.URL.String(), "error", err)
+		return resp, err
+	}
+
+	peek, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxBytes)))
+	if err != nil {
+		slog.Debug("Failed to read response body for wire log", "url", req.URL.String(), "error", err)
+	}
+	resp.Body = &peekedBody{Reader: io.MultiReader(bytes.NewReader(peek), resp.Body), Closer: resp.Body}
+
+	slog.Debug("HTTP response",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"status", resp.Status,
+		"header", redactHeader(resp.Header),
+		"body", truncateBody(peek, t.maxBytes))
+
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport, unless it is the shared
+// http.DefaultTransport, which this client does not own.
+func (t *wireLoggingTransport) CloseIdleConnections() {
+	if t.next == http.DefaultTransport {
+		return
+	}
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// redactHeader returns a copy of header with credentials replaced by "***".
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "***")
+		}
+	}
+	return redacted
+}
+
+// truncateBody renders at most maxBytes of body for logging.
+func truncateBody(body []byte, maxBytes int) string {
+	if len(body) > maxBytes {
+		return string(body[:maxBytes]) + "...(truncated)"
+	}
+	return string(body)
+}
@@ -0,0 +1,69 @@
+package httpext
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ResponseRecorder wraps an http.ResponseWriter to record the status code and number of body bytes written through
+// it, for middleware such as logging and metrics. Flush and Hijack are passed through to the wrapped writer, so SSE
+// streams and connection upgrades keep working, and Unwrap gives http.ResponseController access to it.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	// StatusCode is the final status sent, 200 if the handler wrote a body without calling WriteHeader. Informational
+	// 1xx responses are not recorded.
+	StatusCode int
+	// BytesWritten counts the body bytes written through Write. Bytes written to a hijacked connection are not counted.
+	BytesWritten int64
+
+	wroteHeader bool
+}
+
+// NewResponseRecorder wraps w. The status code starts out as 200, which is what is sent if the handler never calls
+// WriteHeader.
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w, StatusCode: http.StatusOK}
+}
+
+func (rec *ResponseRecorder) WriteHeader(statusCode int) {
+	if !rec.wroteHeader && statusCode >= 200 {
+		rec.StatusCode = statusCode
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *ResponseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.BytesWritten += int64(n)
+	return n, err
+}
+
+// WroteHeader reports whether the response header has been sent, either explicitly or by a first Write.
+func (rec *ResponseRecorder) WroteHeader() bool {
+	return rec.wroteHeader
+}
+
+// Flush sends any buffered data to the client, if the wrapped writer supports flushing.
+func (rec *ResponseRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, if the wrapped writer supports it.
+func (rec *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: the wrapped ResponseWriter cannot be hijacked", http.ErrNotSupported)
+	}
+	return h.Hijack()
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (rec *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
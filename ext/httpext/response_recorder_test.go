@@ -0,0 +1,63 @@
+package httpext
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := NewResponseRecorder(w)
+
+	rec.WriteHeader(http.StatusCreated)
+	rec.WriteHeader(http.StatusInternalServerError)
+	rec.Write([]byte("hello "))
+	rec.Write([]byte("world"))
+	http.NewResponseController(rec).Flush()
+
+	if rec.StatusCode != http.StatusCreated || rec.BytesWritten != 11 || !rec.WroteHeader() {
+		t.Errorf("recorded (%d, %d bytes), want (201, 11 bytes)", rec.StatusCode, rec.BytesWritten)
+	}
+	if !w.Flushed {
+		t.Error("Flush was not passed through")
+	}
+
+	implicit := NewResponseRecorder(httptest.NewRecorder())
+	implicit.Write([]byte("x"))
+	if implicit.StatusCode != http.StatusOK {
+		t.Errorf("status without WriteHeader = %d, want 200", implicit.StatusCode)
+	}
+
+	if _, _, err := rec.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack of a non-hijackable writer: err = %v, want http.ErrNotSupported", err)
+	}
+}
+
+func TestResponseRecorderHijack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := NewResponseRecorder(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		buf.Flush()
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(bufio.NewReader(resp.Body))
+	if !strings.EqualFold(string(body), "hijacked") {
+		t.Errorf("body = %q, want the response written to the hijacked connection", body)
+	}
+}
@@ -0,0 +1,64 @@
+package responses
+
+import (
+	"net/http"
+)
+
+// Machine-readable error codes, for clients to branch on instead of the human-readable message, which may change.
+// Every JSON error response carries one: JsonErrorCode takes it explicitly and the other error responses derive it
+// from their status with ErrorCodeForStatus.
+const (
+	ErrorCodeBadRequest       = "bad_request"
+	ErrorCodeValidationFailed = "validation_failed"
+	ErrorCodeUnauthorized     = "unauthorized"
+	ErrorCodeForbidden        = "forbidden"
+	ErrorCodeNotFound         = "not_found"
+	ErrorCodeConflict         = "conflict"
+	ErrorCodePayloadTooLarge  = "payload_too_large"
+	ErrorCodeRateLimited      = "rate_limited"
+	ErrorCodeInternal         = "internal_error"
+	ErrorCodeUnavailable      = "unavailable"
+	ErrorCodeTimeout          = "timeout"
+)
+
+// statusErrorCodes maps the statuses with a more specific code than ErrorCodeBadRequest or ErrorCodeInternal
+var statusErrorCodes = map[int]string{
+	http.StatusUnauthorized:          ErrorCodeUnauthorized,
+	http.StatusForbidden:             ErrorCodeForbidden,
+	http.StatusNotFound:              ErrorCodeNotFound,
+	http.StatusConflict:              ErrorCodeConflict,
+	http.StatusRequestEntityTooLarge: ErrorCodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   ErrorCodeValidationFailed,
+	http.StatusTooManyRequests:       ErrorCodeRateLimited,
+	http.StatusServiceUnavailable:    ErrorCodeUnavailable,
+	http.StatusGatewayTimeout:        ErrorCodeTimeout,
+}
+
+// ErrorCodeForStatus returns the error code for an error response with the given HTTP status: a specific code where
+// there is one, and otherwise ErrorCodeBadRequest for 4xx statuses and ErrorCodeInternal for any other.
+func ErrorCodeForStatus(statusCode int) string {
+	if code, ok := statusErrorCodes[statusCode]; ok {
+		return code
+	}
+	if statusCode >= 400 && statusCode < 500 {
+		return ErrorCodeBadRequest
+	}
+	return ErrorCodeInternal
+}
+
+// CodedError is the body of a JsonErrorCode response: {"error": {"code": ..., "message": ...}}.
+type CodedError struct {
+	Error CodedErrorDetail `json:"error"`
+}
+
+// CodedErrorDetail is the error of a CodedError.
+type CodedErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// JsonErrorCode writes an error response with the given HTTP status whose body, {"error": {"code": code, "message":
+// message}}, carries a machine-readable code, one of the ErrorCode constants or an application-specific one.
+func JsonErrorCode(w http.ResponseWriter, statusCode int, code string, message string) {
+	writeJSONErrorBody(w, statusCode, CodedError{Error: CodedErrorDetail{Code: code, Message: message}})
+}
@@ -0,0 +1,57 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJsonErrorCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	JsonErrorCode(rec, http.StatusTooManyRequests, ErrorCodeRateLimited, "Slow down")
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	var body map[string]map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal error response %q: %v", rec.Body.String(), err)
+	}
+	if body["error"]["code"] != ErrorCodeRateLimited || body["error"]["message"] != "Slow down" {
+		t.Fatalf("unexpected error response: %s", rec.Body.String())
+	}
+}
+
+func TestErrorResponsesCarryCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		want  string
+	}{
+		{"JsonError", func(w http.ResponseWriter) { JsonError(w, http.StatusBadRequest, "bad input") }, ErrorCodeBadRequest},
+		{"JsonDataNotFound", func(w http.ResponseWriter) { JsonDataNotFound(w, "report not found") }, ErrorCodeNotFound},
+		{"WriteJSONError", func(w http.ResponseWriter) {
+			WriteJSONError(w, http.StatusRequestEntityTooLarge, "Request body too large", "")
+		}, ErrorCodePayloadTooLarge},
+		{"unmapped 5xx", func(w http.ResponseWriter) {
+			JsonError(w, http.StatusBadGateway, "upstream failed")
+		}, ErrorCodeInternal},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.write(rec)
+
+		var body ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: failed to unmarshal error response: %v", tt.name, err)
+		}
+		if body.ErrorCode != tt.want {
+			t.Errorf("%s: error_code = %q, want %q", tt.name, body.ErrorCode, tt.want)
+		}
+		if body.Code != rec.Code || body.Message == "" {
+			t.Errorf("%s: unexpected error response: %+v", tt.name, body)
+		}
+	}
+}
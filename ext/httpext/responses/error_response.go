@@ -1,27 +1,53 @@
 package responses
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
 )
 
+// ErrorResponse is the JSON body of every error response except those of JsonErrorCode, whose body is a CodedError.
+// Its machine-readable code is under "error_code", since "error" holds the message string for clients of JsonError.
 type ErrorResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	// ErrorCode is the machine-readable code of the error, ErrorCodeForStatus(Code) unless set otherwise.
+	ErrorCode string `json:"error_code"`
+	// Error repeats Message for clients of the original JsonError format, {"error": message}. Only JsonError sets it.
+	Error string `json:"error,omitempty"`
 }
 
+// WriteJSONError writes a structured JSON error response with the given HTTP status code, message and optional
+// details.
 func WriteJSONError(w http.ResponseWriter, statusCode int, message string, details string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	err := json.NewEncoder(w).Encode(ErrorResponse{
+	writeErrorResponse(w, ErrorResponse{
 		Code:    statusCode,
 		Message: message,
 		Details: details,
 	})
+}
+
+// writeErrorResponse writes resp, filling in its ErrorCode from its status if it has none
+func writeErrorResponse(w http.ResponseWriter, resp ErrorResponse) {
+	if resp.ErrorCode == "" {
+		resp.ErrorCode = ErrorCodeForStatus(resp.Code)
+	}
+	writeJSONErrorBody(w, resp.Code, resp)
+}
+
+// writeJSONErrorBody is the single writer behind all JSON error responses. The body is marshalled before anything is
+// written, so the Content-Type and status are sent exactly once and a failure can still fall back to a plain 500.
+func writeJSONErrorBody(w http.ResponseWriter, statusCode int, body interface{}) {
+	jsonOutput, err := encodeJson(body, DefaultJsonOptions)
 	if err != nil {
-		slog.Error("Error encoding JSON when marshalling error json", "error", err)
+		slog.Error("Error marshalling error response to JSON", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(jsonOutput); err != nil {
+		slog.Error("Failed to write JSON error response to client", "error", err)
+	}
 }
@@ -1,6 +1,9 @@
 package responses
 
 import (
+	"bytes"
+	"fmt"
+	"html/template"
 	"log/slog"
 	"net/http"
 )
@@ -10,6 +13,7 @@ import (
 // If there's an error during writing the response, it logs the error and returns a 500 Internal Server Error.
 func Html(w http.ResponseWriter, htmlContent string, statusCode int) error {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
 	_, err := w.Write([]byte(htmlContent))
 	if err != nil {
 		slog.Error("Failed to write HTML response to client", "error", err)
@@ -39,3 +43,16 @@ func HtmlNotFound(w http.ResponseWriter, htmlContent string) {
 		http.Error(w, "<h1>Internal Server Error</h1>", http.StatusInternalServerError)
 	}
 }
+
+// HtmlTemplate executes the named template of tmpl with data and writes the result to the client, using the given HTTP
+// status code. The template is rendered into a buffer before anything is written, so a template error produces a clean
+// 500 Internal Server Error instead of a half-written page; the error is logged and returned.
+func HtmlTemplate(w http.ResponseWriter, tmpl *template.Template, name string, data interface{}, statusCode int) error {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		slog.Error("Failed to render HTML template", "template", name, "error", err)
+		http.Error(w, "<h1>Internal Server Error</h1>", http.StatusInternalServerError)
+		return fmt.Errorf("error rendering template %s: %w", name, err)
+	}
+	return Html(w, buf.String(), statusCode)
+}
@@ -0,0 +1,36 @@
+package responses
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHtmlTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("page").Parse(`<h1>{{.Title}}</h1>{{template "missing" .}}`))
+	template.Must(tmpl.New("ok").Parse(`<h1>{{.Title}}</h1>`))
+
+	rec := httptest.NewRecorder()
+	if err := HtmlTemplate(rec, tmpl, "ok", map[string]string{"Title": "Reports & Filings"}, http.StatusAccepted); err != nil {
+		t.Fatalf("HtmlTemplate returned error: %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if got := rec.Body.String(); got != "<h1>Reports &amp; Filings</h1>" {
+		t.Fatalf("body = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := HtmlTemplate(rec, tmpl, "page", map[string]string{"Title": "Half"}, http.StatusOK); err == nil {
+		t.Fatalf("HtmlTemplate with a failing template returned nil error")
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(rec.Body.String(), "Half") {
+		t.Fatalf("half-rendered page was written: %q", rec.Body.String())
+	}
+}
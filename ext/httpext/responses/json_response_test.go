@@ -3,6 +3,8 @@ package responses
 import (
 	"encoding/json"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -39,3 +41,120 @@ func TestDecodeUnicodeEscape(t *testing.T) {
 		t.Fatalf("Expected company name to be %q but got %q", expectedName, company["name"])
 	}
 }
+
+func TestJsonErrorSharesStructuredFormat(t *testing.T) {
+	rec := httptest.NewRecorder()
+	JsonError(rec, http.StatusBadRequest, "bad input")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+
+	var body ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal error response: %v", err)
+	}
+	if body.Code != http.StatusBadRequest || body.Message != "bad input" || body.Error != "bad input" {
+		t.Fatalf("unexpected error response: %+v", body)
+	}
+}
+
+// writeHeaderCounter records every WriteHeader call, to catch superfluous ones
+type writeHeaderCounter struct {
+	*httptest.ResponseRecorder
+	calls int
+}
+
+func (w *writeHeaderCounter) WriteHeader(statusCode int) {
+	w.calls++
+	w.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func TestJsonOKUnmarshalableValue(t *testing.T) {
+	w := &writeHeaderCounter{ResponseRecorder: httptest.NewRecorder()}
+	JsonOK(w, struct {
+		Updates chan int `json:"updates"`
+	}{Updates: make(chan int)})
+
+	if w.calls != 1 {
+		t.Fatalf("WriteHeader called %d times, want 1", w.calls)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not a single JSON error: %v (%q)", err, w.Body.String())
+	}
+}
+
+func TestJsonWithOptions(t *testing.T) {
+	obj := map[string]string{"link": "<a href=\"/r?a=1&b=2\">"}
+
+	rec := httptest.NewRecorder()
+	if err := JsonWith(rec, obj, http.StatusOK, JsonOptions{}); err != nil {
+		t.Fatalf("JsonWith returned error: %v", err)
+	}
+	if got, want := rec.Body.String(), `{"link":"<a href=\"/r?a=1&b=2\">"}`; got != want {
+		t.Fatalf("compact output = %s, want %s", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := Json(rec, obj, http.StatusOK); err != nil {
+		t.Fatalf("Json returned error: %v", err)
+	}
+	want, _ := json.MarshalIndent(obj, JsonEncodePrefix, JsonEncodeIndent)
+	if got := rec.Body.String(); got != string(want) {
+		t.Fatalf("default output = %s, want %s", got, want)
+	}
+}
+
+func TestJsonOKWithCompact(t *testing.T) {
+	obj := map[string]any{"id": 1, "link": "<a>"}
+
+	rec := httptest.NewRecorder()
+	JsonOKWith(rec, obj, CompactJsonOptions)
+	want, _ := json.Marshal(obj)
+	if got := rec.Body.String(); got != string(want) {
+		t.Fatalf("compact output = %s, want %s", got, want)
+	}
+}
+
+// BenchmarkJsonOK compares the default indented encoding with CompactJsonOptions for a small API response.
+func BenchmarkJsonOK(b *testing.B) {
+	type item struct {
+		ID    int      `json:"id"`
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Valid bool     `json:"valid"`
+	}
+	obj := map[string]any{
+		"status": "ok",
+		"items":  []item{{1, "first", []string{"a", "b"}, true}, {2, "second", nil, false}},
+	}
+
+	for _, bench := range []struct {
+		name string
+		opts JsonOptions
+	}{
+		{"indented", DefaultJsonOptions},
+		{"compact", CompactJsonOptions},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				JsonOKWith(discardResponseWriter{}, obj, bench.opts)
+			}
+		})
+	}
+}
+
+// discardResponseWriter is an http.ResponseWriter that throws the response away, so benchmarks measure encoding only
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header         { return http.Header{} }
+func (discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
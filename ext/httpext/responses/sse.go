@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // SendSSEMessageAndCloseLogError sends a Server-Sent Events (SSE) message to the client with the specified message, and then sends a close event.
@@ -37,7 +38,28 @@ func SendSSEError(w http.ResponseWriter, statusCode int, eventType string, messa
 // StreamStringChanToClientSSE streams data from a string channel to the client using Server-Sent Events (SSE).
 // It listens to content and error channels, sending data events to the client as they arrive.
 // The function returns the full content as a single concatenated string.
+//
+// The stream ends as soon as a write fails (the client has gone), so the producer must stop on its own; use
+// StreamStringChanToClientSSEWithCancel to have it told.
 func StreamStringChanToClientSSE(ctx context.Context, w http.ResponseWriter, contentChan <-chan string, errChan <-chan error) string {
+	return streamStringChanToClientSSE(ctx, w, contentChan, errChan, func() {})
+}
+
+// StreamStringChanToClientSSEWithCancel is like StreamStringChanToClientSSE, but calls cancel once streaming stops for
+// any reason: the channels are done, ctx is cancelled, or a write fails because the client disconnected. Producers
+// running under the context that cancel belongs to then stop promptly instead of generating content nobody reads.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(r.Context())
+//	contentChan, errChan := generate(ctx, prompt)
+//	responses.StreamStringChanToClientSSEWithCancel(ctx, cancel, w, contentChan, errChan)
+func StreamStringChanToClientSSEWithCancel(ctx context.Context, cancel context.CancelFunc, w http.ResponseWriter, contentChan <-chan string, errChan <-chan error) string {
+	defer cancel()
+	return streamStringChanToClientSSE(ctx, w, contentChan, errChan, cancel)
+}
+
+func streamStringChanToClientSSE(ctx context.Context, w http.ResponseWriter, contentChan <-chan string, errChan <-chan error, onDisconnect func()) string {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -49,12 +71,16 @@ func StreamStringChanToClientSSE(ctx context.Context, w http.ResponseWriter, con
 	}
 
 	var fullContent strings.Builder
+	clientGone := false
 
 	sendSSEEvent := func(eventType, data string) error {
 		eventMsg := fmt.Sprintf("event: %s\ndata: %s\n\n", eventType, data)
 		_, err := fmt.Fprint(w, eventMsg)
 		if err != nil {
+			// A failed write means the client has disconnected (e.g. a broken pipe); nothing more can be sent
 			slog.Error("Error sending SSE event", "event type", eventType, "error", err)
+			clientGone = true
+			onDisconnect()
 			return err
 		}
 		flusher.Flush()
@@ -92,6 +118,10 @@ streamLoop:
 		}
 	}
 
+	if clientGone {
+		return fullContent.String()
+	}
+
 	// Send final close event
 	err := sendSSEEvent("close", "Stream ended")
 	if err != nil {
@@ -149,3 +179,47 @@ func MimicFullSSEStreamForSingleString(w http.ResponseWriter, summary string) er
 
 	return nil
 }
+
+// MimicChunkedSSEStreamForString mimics a Server-Sent Events (SSE) stream being generated, by sending content one
+// paragraph at a time with delay between paragraphs, followed by a close event. It stops early, returning ctx.Err(),
+// when ctx is cancelled (e.g. the client disconnected).
+func MimicChunkedSSEStreamForString(ctx context.Context, w http.ResponseWriter, content string, delay time.Duration) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, _ := w.(http.Flusher)
+
+	paragraphs := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n")
+	first := true
+	for _, paragraph := range paragraphs {
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+
+		if !first {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		first = false
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", strings.ReplaceAll(paragraph, "\n", "<br>")); err != nil {
+			return fmt.Errorf("error writing data: %w", err)
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "event: close\ndata: Stream ended\n\n"); err != nil {
+		return fmt.Errorf("error writing event: %w", err)
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return nil
+}
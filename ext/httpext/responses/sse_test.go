@@ -0,0 +1,87 @@
+package responses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// disconnectedWriter fails every write as if the client had closed the connection
+type disconnectedWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w disconnectedWriter) Write([]byte) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+func (w disconnectedWriter) Flush() {}
+
+func TestStreamStringChanToClientSSECancelsOnDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	contentChan := make(chan string)
+	errChan := make(chan error)
+
+	// The producer keeps generating until its context is cancelled
+	producerDone := make(chan struct{})
+	go func() {
+		defer close(producerDone)
+		for {
+			select {
+			case contentChan <- "token":
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var w http.ResponseWriter = disconnectedWriter{httptest.NewRecorder()}
+	StreamStringChanToClientSSEWithCancel(ctx, cancel, w, contentChan, errChan)
+
+	select {
+	case <-producerDone:
+	case <-time.After(time.Second):
+		t.Fatalf("producer was not cancelled after the client disconnected")
+	}
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatalf("ctx.Err() = %v, want context.Canceled", ctx.Err())
+	}
+}
+
+func TestMimicChunkedSSEStreamForString(t *testing.T) {
+	rec := httptest.NewRecorder()
+	content := "First paragraph\nsecond line\r\n\r\nSecond paragraph\n\n\n\nThird paragraph"
+	if err := MimicChunkedSSEStreamForString(context.Background(), rec, content, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "data: First paragraph<br>second line\n\n" +
+		"data: Second paragraph\n\n" +
+		"data: Third paragraph\n\n" +
+		"event: close\ndata: Stream ended\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("stream =\n%q\nwant\n%q", got, want)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+}
+
+func TestMimicChunkedSSEStreamForStringCancel(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	// The hour long delay means only the first paragraph is sent before the cancel
+	err := MimicChunkedSSEStreamForString(ctx, rec, "First\n\nSecond", time.Hour)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if got := rec.Body.String(); got != "data: First\n\n" {
+		t.Errorf("stream = %q, want only the first paragraph and no close event", got)
+	}
+}
@@ -0,0 +1,62 @@
+package responses
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrSSEWriterClosed is returned when writing to an SSEWriter after Close.
+var ErrSSEWriterClosed = errors.New("sse writer closed")
+
+// SSEWriter adapts a Server-Sent Events (SSE) stream to io.Writer, so any io.Writer based producer (a template, a
+// logger, io.Copy) can stream to the client. Each Write is sent as a single "message" event and flushed; as in the
+// other SSE helpers, newlines in the data are sent as <br>. Close sends the close event.
+type SSEWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	closed  bool
+}
+
+// NewSSEWriter sets the SSE headers on w and returns a writer streaming to it. If w does not support flushing, events
+// are still written but only reach the client when the handler returns.
+func NewSSEWriter(w http.ResponseWriter) *SSEWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, _ := w.(http.Flusher)
+	return &SSEWriter{w: w, flusher: flusher}
+}
+
+// Write sends p as a single message event and flushes it to the client.
+func (s *SSEWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, ErrSSEWriterClosed
+	}
+	if err := s.send("message", string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close sends the close event that ends the stream. Calling Close more than once has no further effect.
+func (s *SSEWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.send("close", "Stream ended")
+}
+
+func (s *SSEWriter) send(eventType, data string) error {
+	data = strings.ReplaceAll(data, "\n", "<br>")
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", eventType, data); err != nil {
+		return fmt.Errorf("error writing SSE %s event: %w", eventType, err)
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
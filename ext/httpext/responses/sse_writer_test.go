@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSSEWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sse := NewSSEWriter(rec)
+
+	if _, err := fmt.Fprintf(sse, "line one\nline two"); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if err := sse.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if _, err := sse.Write([]byte("late")); err != ErrSSEWriterClosed {
+		t.Fatalf("Write after Close returned %v, want ErrSSEWriterClosed", err)
+	}
+
+	want := "event: message\ndata: line one<br>line two\n\nevent: close\ndata: Stream ended\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("stream = %q, want %q", got, want)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	if !rec.Flushed {
+		t.Fatalf("events were not flushed")
+	}
+}
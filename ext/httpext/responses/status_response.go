@@ -0,0 +1,30 @@
+package responses
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// NoContent writes a 204 No Content response with no body.
+func NoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Created writes the provided object as a JSON response with a 201 Created status code, setting the Location header
+// to the URL of the created resource. An empty location omits the header.
+func Created(w http.ResponseWriter, location string, obj interface{}) {
+	if location != "" {
+		w.Header().Set("Location", location)
+	}
+	if err := Json(w, obj, http.StatusCreated); err != nil {
+		slog.Error("Failed to return created object as JSON", "error", err)
+	}
+}
+
+// Accepted writes the provided object as a JSON response with a 202 Accepted status code, for requests whose
+// processing continues after the response is sent.
+func Accepted(w http.ResponseWriter, obj interface{}) {
+	if err := Json(w, obj, http.StatusAccepted); err != nil {
+		slog.Error("Failed to return accepted object as JSON", "error", err)
+	}
+}
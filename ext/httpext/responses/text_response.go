@@ -1,6 +1,8 @@
 package responses
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 )
@@ -29,3 +31,42 @@ func TextOK(w http.ResponseWriter, textContent string) {
 		return
 	}
 }
+
+// TextStream streams plain text to the client with a 200 OK status, writing and flushing each chunk received from
+// chunks as it arrives, so clients can render a long output progressively instead of waiting for all of it. It is the
+// plain chunked counterpart of the SSE helpers, for clients that do not want event-stream framing. It returns nil once
+// chunks is closed, ctx.Err() if ctx is cancelled first, or the error of a failed write, e.g. because the client has
+// disconnected; in the last two cases the producer must be stopped by the caller. If w does not support flushing, the
+// chunks are still written but only reach the client when the handler returns.
+func TextStream(ctx context.Context, w http.ResponseWriter, chunks <-chan string) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	// Browsers hold back text/plain to sniff its type unless told not to
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return nil
+			}
+			if chunk == "" {
+				continue
+			}
+			if _, err := w.Write([]byte(chunk)); err != nil {
+				slog.Error("Failed to write text stream to client", "error", err)
+				return fmt.Errorf("error writing text chunk: %w", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
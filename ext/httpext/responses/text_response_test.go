@@ -0,0 +1,78 @@
+package responses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flushCounter records the body written at each Flush
+type flushCounter struct {
+	*httptest.ResponseRecorder
+	flushed []string
+}
+
+func (w *flushCounter) Flush() {
+	w.flushed = append(w.flushed, w.Body.String())
+	w.ResponseRecorder.Flush()
+}
+
+func TestTextStream(t *testing.T) {
+	chunks := make(chan string, 3)
+	chunks <- "first "
+	chunks <- ""
+	chunks <- "second"
+	close(chunks)
+
+	w := &flushCounter{ResponseRecorder: httptest.NewRecorder()}
+	if err := TextStream(context.Background(), w, chunks); err != nil {
+		t.Fatalf("TextStream returned %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "first second" {
+		t.Errorf("response = %d %q, want 200 %q", w.Code, w.Body.String(), "first second")
+	}
+	want := []string{"", "first ", "first second"}
+	if len(w.flushed) != len(want) {
+		t.Fatalf("flushed %q, want %q", w.flushed, want)
+	}
+	for i := range want {
+		if w.flushed[i] != want[i] {
+			t.Fatalf("flushed %q, want %q", w.flushed, want)
+		}
+	}
+}
+
+func TestTextStreamStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := make(chan string)
+	done := make(chan error)
+	go func() {
+		done <- TextStream(ctx, httptest.NewRecorder(), chunks)
+	}()
+
+	chunks <- "partial"
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("TextStream after cancel returned %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TextStream did not stop when ctx was cancelled")
+	}
+
+	chunks = make(chan string, 1)
+	chunks <- "lost"
+	err := TextStream(context.Background(), disconnectedWriter{httptest.NewRecorder()}, chunks)
+	if !errors.Is(err, syscall.EPIPE) {
+		t.Fatalf("TextStream to a disconnected client returned %v, want EPIPE", err)
+	}
+}
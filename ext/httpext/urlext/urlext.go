@@ -2,6 +2,8 @@ package urlext
 
 import (
 	"fmt"
+	"golang.org/x/net/publicsuffix"
+	"net"
 	"net/url"
 	"strings"
 )
@@ -20,3 +22,96 @@ func ExtractSubdomain(urlString string) (string, error) {
 
 	return "", fmt.Errorf("no subdomain found")
 }
+
+// BuildURL returns base, which must be an absolute URL, with params as its query string, replacing any query base
+// already has. Parameters are encoded in key order, so the same params always produce the same URL.
+func BuildURL(base string, params map[string]string) (string, error) {
+	parsedURL, err := parseAbsoluteURL(base)
+	if err != nil {
+		return "", err
+	}
+
+	parsedURL.RawQuery = encodeParams(url.Values{}, params)
+	return parsedURL.String(), nil
+}
+
+// AddParams returns raw, which must be an absolute URL, with params merged into its query string. A parameter already
+// present is replaced by the value in params; all others are kept. The query is re-encoded in key order, so the result
+// does not depend on the order of raw's existing parameters.
+func AddParams(raw string, params map[string]string) (string, error) {
+	parsedURL, err := parseAbsoluteURL(raw)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := url.ParseQuery(parsedURL.RawQuery)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse query of %s: %v", raw, err)
+	}
+	parsedURL.RawQuery = encodeParams(query, params)
+	return parsedURL.String(), nil
+}
+
+func parseAbsoluteURL(raw string) (*url.URL, error) {
+	parsedURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %v", err)
+	}
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return nil, fmt.Errorf("URL %q is not absolute", raw)
+	}
+	return parsedURL, nil
+}
+
+// encodeParams sets params on query and encodes it; url.Values.Encode sorts by key
+func encodeParams(query url.Values, params map[string]string) string {
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	return query.Encode()
+}
+
+// RegistrableDomain returns the registrable domain (eTLD+1) of urlString's host, e.g. "example.co.uk" for
+// "https://api.example.co.uk/v1", so URLs can be grouped by site rather than by exact host. It fails for IP addresses
+// and for hosts that are themselves a public suffix.
+func RegistrableDomain(urlString string) (string, error) {
+	host, err := domainHost(urlString)
+	if err != nil {
+		return "", err
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return "", fmt.Errorf("failed to find registrable domain of %s: %v", host, err)
+	}
+	return domain, nil
+}
+
+// PublicSuffix returns the public suffix (eTLD) of urlString's host, e.g. "co.uk" for "https://api.example.co.uk/v1".
+// Hosts under a suffix missing from the list get their last label, following the list's default rule.
+func PublicSuffix(urlString string) (string, error) {
+	host, err := domainHost(urlString)
+	if err != nil {
+		return "", err
+	}
+
+	suffix, _ := publicsuffix.PublicSuffix(host)
+	return suffix, nil
+}
+
+// domainHost returns the normalized host name of urlString, failing if it has none or is an IP address
+func domainHost(urlString string) (string, error) {
+	parsedURL, err := url.Parse(urlString)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %v", err)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsedURL.Hostname()), ".")
+	if host == "" {
+		return "", fmt.Errorf("no host found in %q", urlString)
+	}
+	if net.ParseIP(host) != nil {
+		return "", fmt.Errorf("host %s is an IP address, not a domain", host)
+	}
+	return host, nil
+}
@@ -0,0 +1,79 @@
+package urlext
+
+import "testing"
+
+func TestBuildURL(t *testing.T) {
+	got, err := BuildURL("https://efts.sec.gov/LATEST/search-index?stale=1", map[string]string{
+		"q":     "10-K annual",
+		"dateb": "2024-01-01",
+		"cik":   "0000320193",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "https://efts.sec.gov/LATEST/search-index?cik=0000320193&dateb=2024-01-01&q=10-K+annual"
+	if got != want {
+		t.Errorf("BuildURL = %q, want %q", got, want)
+	}
+
+	if _, err := BuildURL("/relative/path", nil); err == nil {
+		t.Error("expected an error for a relative URL")
+	}
+}
+
+func TestAddParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		params map[string]string
+		want   string
+	}{
+		{"no query", "https://example.com/a", map[string]string{"b": "2", "a": "1"}, "https://example.com/a?a=1&b=2"},
+		{"merge", "https://example.com/a?z=9&c=3", map[string]string{"a": "1"}, "https://example.com/a?a=1&c=3&z=9"},
+		{"replace", "https://example.com/a?a=old&a=older", map[string]string{"a": "new"}, "https://example.com/a?a=new"},
+		{"fragment kept", "https://example.com/a?b=2#top", map[string]string{"a": "1"}, "https://example.com/a?a=1&b=2#top"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AddParams(tt.raw, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("AddParams = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantDomain string
+		wantSuffix string
+	}{
+		{"https://api.example.com/v1", "example.com", "com"},
+		{"https://www.example.com", "example.com", "com"},
+		{"https://API.Example.co.uk.:8443/x", "example.co.uk", "co.uk"},
+		{"http://foo.bar.github.io", "bar.github.io", "github.io"},
+	}
+	for _, tt := range tests {
+		domain, err := RegistrableDomain(tt.url)
+		if err != nil {
+			t.Fatalf("RegistrableDomain(%q): %v", tt.url, err)
+		}
+		suffix, err := PublicSuffix(tt.url)
+		if err != nil {
+			t.Fatalf("PublicSuffix(%q): %v", tt.url, err)
+		}
+		if domain != tt.wantDomain || suffix != tt.wantSuffix {
+			t.Errorf("%q = (%q, %q), want (%q, %q)", tt.url, domain, suffix, tt.wantDomain, tt.wantSuffix)
+		}
+	}
+
+	for _, url := range []string{"http://127.0.0.1/", "http://[::1]:80/", "https://co.uk", "/relative"} {
+		if domain, err := RegistrableDomain(url); err == nil {
+			t.Errorf("RegistrableDomain(%q) = %q, want an error", url, domain)
+		}
+	}
+}
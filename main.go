@@ -2,40 +2,103 @@ package main
 
 import (
         "context"
+        "errors"
         "flag"
-        "fmt"
         "log/slog"
         "os"
         "os/signal"
         "syscall"
         "text/tabwriter"
+        "time"
         "vmuser/cmd"
         "vmuser/config"
+        "vmuser/pkg/reports"
 )
 
 func main() {
-        configFile := flag.String("config", "vmuser.toml", "Path to the configuration file")
+        configFile := flag.String("config", "", "Path to the configuration file (default: search ./vmuser.toml, $XDG_CONFIG_HOME/vmuser/, /etc/vmuser/)")
         tui := flag.Bool("tui", false, "Run TUI")
         addReport := flag.String("add-report", "", "Path to the report file to add")
+        updateExisting := flag.Bool("update-existing", false, "When adding a report whose content is already stored, update the existing report instead of skipping it")
+        addReportsDir := flag.String("add-reports-dir", "", "Path to a directory of report files to add")
         getReport := flag.Int64("get-report", -1, "ID of the report to retrieve")
         listReports := flag.Bool("list-reports", false, "List all reports")
+        followOperations := flag.Bool("follow-operations", false, "Print virtual filesystem operations as they are logged, until interrupted")
+        since := flag.String("since", "", "Only list reports created at or after this time (RFC3339 or relative, e.g. 7d)")
+        until := flag.String("until", "", "Only list reports created before this time (RFC3339 or relative, e.g. 1d)")
+        pruneReports := flag.Bool("prune-reports", false, "Delete reports older than -older-than")
+        olderThan := flag.String("older-than", "", "Retention period for -prune-reports, e.g. 30d or 720h")
+        status := flag.Bool("status", false, "Print a summary of the configuration, database and storage health")
+        printConfig := flag.Bool("print-config", false, "Print the effective configuration, with secrets redacted, and where each value came from")
+        verifyDB := flag.Bool("verify-db", false, "Check the database for missing tables, columns, indexes and triggers")
+        repairDB := flag.Bool("repair-db", false, "Create any missing tables, columns, indexes and triggers in the database")
+        noColor := flag.Bool("no-color", false, "Disable colored output (color is only used when stdout is a terminal)")
+        configFormat := flag.String("config-format", cmd.ConfigFormatTOML, "Output format for -print-config: toml or json")
 
         flag.Parse()
 
         appContext, stop := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill, syscall.SIGTERM)
         defer stop()
 
-        cfg := config.GetVMUserConfig(*configFile)
+        cfg, err := config.LoadVMUserConfig(*configFile)
+        if err != nil {
+                slog.Error("Error loading configuration", "error", err)
+                if !*status && !*printConfig {
+                        os.Exit(1)
+                }
+        }
+
+        if *printConfig {
+                if err := cmd.PrintConfig(os.Stdout, cfg, *configFormat); err != nil {
+                        slog.Error("Error printing configuration", "error", err)
+                        os.Exit(1)
+                }
+                return
+        }
+
+        if *status {
+                report, err := cmd.Status(appContext, cfg)
+                cmd.DisplayStatus(os.Stdout, report)
+                if err != nil {
+                        slog.Error("Status check failed", "error", err)
+                        os.Exit(1)
+                }
+                return
+        }
+
+        if *verifyDB || *repairDB {
+                if err := cmd.VerifySchema(appContext, cfg, os.Stdout, *repairDB); err != nil {
+                        slog.Error("Database schema check failed", "error", err)
+                        os.Exit(1)
+                }
+                return
+        }
 
         // Handle report commands
         if *addReport != "" {
-                if err := cmd.AddReport(appContext, cfg, *addReport); err != nil {
+                if err := cmd.AddReport(appContext, cfg, *addReport, *updateExisting); err != nil {
+                        if errors.Is(err, reports.ErrReportExists) {
+                                slog.Info("Report already exists, skipping", "file", *addReport)
+                                return
+                        }
                         slog.Error("Error adding report", "error", err, "file", *addReport)
                         os.Exit(1)
                 }
                 return
         }
 
+        if *addReportsDir != "" {
+                ids, err := cmd.AddReportsDir(appContext, cfg, *addReportsDir)
+                if len(ids) > 0 {
+                        slog.Info("Added reports", "count", len(ids), "ids", ids)
+                }
+                if err != nil {
+                        slog.Error("Error adding reports", "error", err, "dir", *addReportsDir)
+                        os.Exit(1)
+                }
+                return
+        }
+
         if *getReport >= 0 {
                 report, err := cmd.GetReportByID(appContext, cfg, *getReport)
                 if err != nil {
@@ -43,30 +106,62 @@ func main() {
                         os.Exit(1)
                 }
                 w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-                cmd.DisplayReport(w, report)
+                cmd.DisplayReport(w, report, cmd.NewStyle(os.Stdout, *noColor))
                 w.Flush()
                 return
         }
 
         if *listReports {
-                reports, err := cmd.ListAllReports(appContext, cfg)
+                var reportList []reports.Report
+                var err error
+                if *since == "" && *until == "" {
+                        reportList, err = cmd.ListAllReports(appContext, cfg)
+                } else {
+                        now := time.Now()
+                        from, fromErr := cmd.ParseTimeFlag(*since, now)
+                        to, toErr := cmd.ParseTimeFlag(*until, now)
+                        if fromErr != nil || toErr != nil {
+                                slog.Error("Error parsing report time range", "since", fromErr, "until", toErr)
+                                os.Exit(1)
+                        }
+                        if *until == "" {
+                                to = now
+                        }
+                        reportList, err = cmd.ListReportsBetween(appContext, cfg, from, to)
+                }
                 if err != nil {
                         slog.Error("Error listing reports", "error", err)
                         os.Exit(1)
                 }
                 w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-                fmt.Fprintln(w, "ID\tFilename\tCreated At")
-                fmt.Fprintln(w, "---\t--------\t----------")
-                for _, r := range reports {
-                        fmt.Fprintf(w, "%d\t%s\t%s\n",
-                                r.ID,
-                                r.Filename,
-                                r.CreatedAt.Format("2006-01-02 15:04:05"))
-                }
+                cmd.DisplayReportList(w, reportList, cmd.NewStyle(os.Stdout, *noColor))
                 w.Flush()
                 return
         }
 
+        if *pruneReports {
+                retention, err := cmd.ParseRelativeDuration(*olderThan)
+                if err != nil {
+                        slog.Error("Error parsing -older-than", "error", err)
+                        os.Exit(1)
+                }
+                deleted, err := cmd.PruneReports(appContext, cfg, retention)
+                if err != nil {
+                        slog.Error("Error pruning reports", "error", err)
+                        os.Exit(1)
+                }
+                slog.Info("Pruned reports", "deleted", deleted, "olderThan", *olderThan)
+                return
+        }
+
+        if *followOperations {
+                if err := cmd.FollowOperations(appContext, cfg, os.Stdout); err != nil {
+                        slog.Error("Error following operations", "error", err)
+                        os.Exit(1)
+                }
+                return
+        }
+
         if *tui {
                 if err := cmd.TUI(appContext, cfg); err != nil {
                         slog.Error("Error running application", "error", err)
@@ -0,0 +1,194 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"vmuser/config"
+)
+
+// DefaultMaxTokens bounds the length of a completion for providers that require a limit
+const DefaultMaxTokens = 4096
+
+// maxEventSize bounds a single streamed event; provider chunks are far smaller
+const maxEventSize = 1 << 20
+
+// ErrNoProvider is returned by NewClient when no LLM API key is configured
+var ErrNoProvider = errors.New("no LLM provider configured")
+
+// Client completes prompts against an LLM provider.
+type Client interface {
+	// Complete returns the full completion for prompt.
+	Complete(ctx context.Context, prompt string) (string, error)
+
+	// CompleteStream streams the completion for prompt as it is generated. Text chunks are sent on the first channel;
+	// at most one error is sent on the second. Both channels are closed once the completion ends, an error occurs, or
+	// ctx is cancelled, which also aborts the request to the provider. The channels can be handed directly to
+	// responses.StreamStringChanToClientSSE.
+	CompleteStream(ctx context.Context, prompt string) (<-chan string, <-chan error)
+}
+
+// Option configures the client built by NewClient.
+type Option func(*streamClient)
+
+// WithModel overrides the provider's default model.
+func WithModel(model string) Option {
+	return func(c *streamClient) {
+		c.model = model
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens to generate.
+func WithMaxTokens(maxTokens int) Option {
+	return func(c *streamClient) {
+		c.maxTokens = maxTokens
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to call the provider.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *streamClient) {
+		c.httpClient = client
+	}
+}
+
+// WithBaseURL overrides the provider's API base URL, e.g. for a proxy or a compatible self-hosted endpoint.
+func WithBaseURL(baseURL string) Option {
+	return func(c *streamClient) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// NewClient returns a client for the first provider with an API key set in cfg, checked in the order Anthropic,
+// OpenAI, Gemini. ErrNoProvider is returned if none is.
+func NewClient(cfg config.LLM, options ...Option) (Client, error) {
+	var c *streamClient
+	switch {
+	case cfg.AnthropicKey != "":
+		c = newAnthropicClient(cfg.AnthropicKey)
+	case cfg.OpenAIKey != "":
+		c = newOpenAIClient(cfg.OpenAIKey)
+	case cfg.GeminiKey != "":
+		c = newGeminiClient(cfg.GeminiKey)
+	default:
+		return nil, ErrNoProvider
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+	return c, nil
+}
+
+// provider adapts the shared streaming client to a specific API.
+type provider interface {
+	name() string
+	// newRequest builds the streaming completion request for prompt
+	newRequest(ctx context.Context, c *streamClient, prompt string) (*http.Request, error)
+	// parseEvent extracts the text from one server-sent event's data, reporting done once the stream is complete
+	parseEvent(data []byte) (text string, done bool, err error)
+}
+
+// streamClient implements Client for providers that stream completions as server-sent events
+type streamClient struct {
+	provider   provider
+	apiKey     string
+	baseURL    string
+	model      string
+	maxTokens  int
+	httpClient *http.Client
+}
+
+func (c *streamClient) Complete(ctx context.Context, prompt string) (string, error) {
+	contentChan, errChan := c.CompleteStream(ctx, prompt)
+
+	var completion strings.Builder
+	for chunk := range contentChan {
+		completion.WriteString(chunk)
+	}
+	if err := <-errChan; err != nil {
+		return "", err
+	}
+	return completion.String(), nil
+}
+
+func (c *streamClient) CompleteStream(ctx context.Context, prompt string) (<-chan string, <-chan error) {
+	contentChan := make(chan string)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(contentChan)
+		defer close(errChan)
+
+		if err := c.stream(ctx, prompt, contentChan); err != nil {
+			errChan <- fmt.Errorf("%s completion: %w", c.provider.name(), err)
+		}
+	}()
+
+	return contentChan, errChan
+}
+
+func (c *streamClient) stream(ctx context.Context, prompt string, contentChan chan<- string) error {
+	req, err := c.provider.newRequest(ctx, c, prompt)
+	if err != nil {
+		return fmt.Errorf("error building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxEventSize)
+	for scanner.Scan() {
+		data, ok := bytes.CutPrefix(scanner.Bytes(), []byte("data:"))
+		if !ok {
+			continue
+		}
+		text, done, err := c.provider.parseEvent(bytes.TrimSpace(data))
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			select {
+			case contentChan <- text:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("error reading stream: %w", err)
+	}
+	return nil
+}
+
+// newJSONRequest builds a POST request with body marshalled as JSON
+func newJSONRequest(ctx context.Context, url string, body any) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+}
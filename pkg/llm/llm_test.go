@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"vmuser/config"
+)
+
+func sseServer(t *testing.T, wantPath string, events ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, wantPath) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientProviders(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.LLM
+		path   string
+		events []string
+	}{
+		{
+			name: "anthropic",
+			cfg:  config.LLM{AnthropicKey: "key", OpenAIKey: "ignored"},
+			path: "/v1/messages",
+			events: []string{
+				`{"type":"message_start"}`,
+				`{"type":"content_block_delta","delta":{"type":"text_delta","text":"Hello, "}}`,
+				`{"type":"content_block_delta","delta":{"type":"text_delta","text":"world"}}`,
+				`{"type":"message_stop"}`,
+			},
+		},
+		{
+			name: "openai",
+			cfg:  config.LLM{OpenAIKey: "key"},
+			path: "/v1/chat/completions",
+			events: []string{
+				`{"choices":[{"delta":{"content":"Hello, "}}]}`,
+				`{"choices":[{"delta":{"content":"world"}}]}`,
+				`[DONE]`,
+			},
+		},
+		{
+			name: "gemini",
+			cfg:  config.LLM{GeminiKey: "key"},
+			path: "/v1beta/models/",
+			events: []string{
+				`{"candidates":[{"content":{"parts":[{"text":"Hello, "}]}}]}`,
+				`{"candidates":[{"content":{"parts":[{"text":"world"}]}}]}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sseServer(t, tt.path, tt.events...)
+			client, err := NewClient(tt.cfg, WithBaseURL(server.URL))
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			got, err := client.Complete(context.Background(), "Say hello")
+			if err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			if got != "Hello, world" {
+				t.Errorf("Complete = %q, want %q", got, "Hello, world")
+			}
+		})
+	}
+}
+
+func TestNewClientNoProvider(t *testing.T) {
+	if _, err := NewClient(config.LLM{}); !errors.Is(err, ErrNoProvider) {
+		t.Fatalf("NewClient err = %v, want ErrNoProvider", err)
+	}
+}
+
+func TestCompleteStreamErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(config.LLM{OpenAIKey: "bad"}, WithBaseURL(server.URL))
+	_, err := client.Complete(context.Background(), "hi")
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Complete err = %v, want status 401", err)
+	}
+}
+
+func TestCompleteStreamCancel(t *testing.T) {
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for {
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"tick\"}}]}\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				close(stop)
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(config.LLM{OpenAIKey: "key"}, WithBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	contentChan, errChan := client.CompleteStream(ctx, "count forever")
+
+	<-contentChan
+	cancel()
+	for range contentChan {
+	}
+	if err := <-errChan; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	select {
+	case <-stop:
+	case <-time.After(2 * time.Second):
+		t.Fatal("provider request was not aborted after cancel")
+	}
+}
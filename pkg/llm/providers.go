@@ -0,0 +1,206 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Default API endpoints and models for each provider, overridable with WithBaseURL and WithModel
+const (
+	AnthropicBaseURL = "https://api.anthropic.com"
+	AnthropicModel   = "claude-3-5-sonnet-latest"
+	AnthropicVersion = "2023-06-01"
+
+	OpenAIBaseURL = "https://api.openai.com"
+	OpenAIModel   = "gpt-4o-mini"
+
+	GeminiBaseURL = "https://generativelanguage.googleapis.com"
+	GeminiModel   = "gemini-1.5-flash"
+)
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func newAnthropicClient(apiKey string) *streamClient {
+	return &streamClient{
+		provider:   anthropic{},
+		apiKey:     apiKey,
+		baseURL:    AnthropicBaseURL,
+		model:      AnthropicModel,
+		maxTokens:  DefaultMaxTokens,
+		httpClient: &http.Client{},
+	}
+}
+
+type anthropic struct{}
+
+func (anthropic) name() string { return "anthropic" }
+
+func (anthropic) newRequest(ctx context.Context, c *streamClient, prompt string) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, c.baseURL+"/v1/messages", map[string]any{
+		"model":      c.model,
+		"max_tokens": c.maxTokens,
+		"stream":     true,
+		"messages":   []message{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", AnthropicVersion)
+	return req, nil
+}
+
+func (anthropic) parseEvent(data []byte) (string, bool, error) {
+	var event struct {
+		Type  string `json:"type"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", false, fmt.Errorf("error decoding event: %w", err)
+	}
+
+	switch event.Type {
+	case "content_block_delta":
+		if event.Delta.Type == "text_delta" {
+			return event.Delta.Text, false, nil
+		}
+	case "message_stop":
+		return "", true, nil
+	case "error":
+		return "", false, fmt.Errorf("%s: %s", event.Error.Type, event.Error.Message)
+	}
+	return "", false, nil
+}
+
+func newOpenAIClient(apiKey string) *streamClient {
+	return &streamClient{
+		provider:   openAI{},
+		apiKey:     apiKey,
+		baseURL:    OpenAIBaseURL,
+		model:      OpenAIModel,
+		maxTokens:  DefaultMaxTokens,
+		httpClient: &http.Client{},
+	}
+}
+
+type openAI struct{}
+
+func (openAI) name() string { return "openai" }
+
+func (openAI) newRequest(ctx context.Context, c *streamClient, prompt string) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, c.baseURL+"/v1/chat/completions", map[string]any{
+		"model":                 c.model,
+		"max_completion_tokens": c.maxTokens,
+		"stream":                true,
+		"messages":              []message{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	return req, nil
+}
+
+func (openAI) parseEvent(data []byte) (string, bool, error) {
+	if string(data) == "[DONE]" {
+		return "", true, nil
+	}
+
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return "", false, fmt.Errorf("error decoding chunk: %w", err)
+	}
+	if chunk.Error != nil {
+		return "", false, errors.New(chunk.Error.Message)
+	}
+
+	var text strings.Builder
+	for _, choice := range chunk.Choices {
+		text.WriteString(choice.Delta.Content)
+	}
+	return text.String(), false, nil
+}
+
+func newGeminiClient(apiKey string) *streamClient {
+	return &streamClient{
+		provider:   gemini{},
+		apiKey:     apiKey,
+		baseURL:    GeminiBaseURL,
+		model:      GeminiModel,
+		maxTokens:  DefaultMaxTokens,
+		httpClient: &http.Client{},
+	}
+}
+
+type gemini struct{}
+
+func (gemini) name() string { return "gemini" }
+
+func (gemini) newRequest(ctx context.Context, c *streamClient, prompt string) (*http.Request, error) {
+	endpoint := fmt.Sprintf("%s/v1beta/models/%s:streamGenerateContent?alt=sse", c.baseURL, url.PathEscape(c.model))
+	req, err := newJSONRequest(ctx, endpoint, map[string]any{
+		"contents": []map[string]any{
+			{"role": "user", "parts": []map[string]string{{"text": prompt}}},
+		},
+		"generationConfig": map[string]any{"maxOutputTokens": c.maxTokens},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-goog-api-key", c.apiKey)
+	return req, nil
+}
+
+func (gemini) parseEvent(data []byte) (string, bool, error) {
+	var chunk struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return "", false, fmt.Errorf("error decoding chunk: %w", err)
+	}
+	if chunk.Error != nil {
+		return "", false, errors.New(chunk.Error.Message)
+	}
+
+	// Gemini ends the stream by closing the connection rather than with a final event
+	var text strings.Builder
+	for _, candidate := range chunk.Candidates {
+		for _, part := range candidate.Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String(), false, nil
+}
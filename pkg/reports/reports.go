@@ -2,10 +2,14 @@ package reports
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"time"
+	"vmuser/ext/app"
 )
 
 type Report struct {
@@ -16,22 +20,35 @@ type Report struct {
 	UpdatedAt time.Time
 }
 
-// AddReportToDatabase adds a new report to the database
-func AddReportToDatabase(ctx context.Context, db *sql.DB, reportPath string) error {
+// ErrReportExists is returned when a report with identical content is already stored.
+var ErrReportExists = errors.New("report with identical content already exists")
+
+// AddReportToDatabase adds a new report to the database and returns its ID. Reports are deduplicated by a SHA-256 of
+// their content: if an identical report already exists, its ID is returned together with ErrReportExists, unless
+// updateExisting is set, in which case the existing report's filename and updated_at are refreshed in place.
+func AddReportToDatabase(ctx context.Context, db *sql.DB, reportPath string, updateExisting bool) (int64, error) {
 	if err := ensureReportTable(ctx, db); err != nil {
-		return err
+		return 0, err
 	}
 
-	return insertReport(ctx, db, reportPath)
+	return insertReport(ctx, db, reportPath, updateExisting)
+}
+
+// EnsureSchema creates the reports table, or migrates an existing one, up front. Writes do this themselves, so it is
+// only needed before reading from a database no report has been written to yet.
+func EnsureSchema(ctx context.Context, db *sql.DB) error {
+	return ensureReportTable(ctx, db)
 }
 
-// ensureReportTable creates the reports table if it doesn't exist
+// ensureReportTable creates the reports table if it doesn't exist, and adds the content hash column and its unique
+// index to tables created before reports were deduplicated
 func ensureReportTable(ctx context.Context, db *sql.DB) error {
 	createTableSQL := `
 	CREATE TABLE IF NOT EXISTS reports (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		content TEXT NOT NULL,
 		filename TEXT NOT NULL,
+		content_hash TEXT,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
@@ -41,33 +58,213 @@ func ensureReportTable(ctx context.Context, db *sql.DB) error {
 		return fmt.Errorf("error creating reports table: %w", err)
 	}
 
+	var hasHashColumn int
+	err = db.QueryRowContext(ctx, `
+	SELECT COUNT(*) FROM pragma_table_info('reports') WHERE name = 'content_hash';`).Scan(&hasHashColumn)
+	if err != nil {
+		return fmt.Errorf("error inspecting reports table: %w", err)
+	}
+	if hasHashColumn == 0 {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE reports ADD COLUMN content_hash TEXT;`); err != nil {
+			return fmt.Errorf("error adding content_hash column to reports table: %w", err)
+		}
+	}
+
+	_, err = db.ExecContext(ctx, `
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_content_hash ON reports(content_hash);`)
+	if err != nil {
+		return fmt.Errorf("error creating reports content hash index: %w", err)
+	}
+
+	return backfillContentHashes(ctx, db)
+}
+
+// backfillContentHashes sets the content hash of reports stored before the column existed, so they take part in
+// deduplication. Of several such reports with the same content only the oldest gets the hash; the unique index leaves
+// the others without one, as they were.
+func backfillContentHashes(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+	SELECT id, content FROM reports WHERE content_hash IS NULL ORDER BY id;`)
+	if err != nil {
+		return fmt.Errorf("error querying reports without a content hash: %w", err)
+	}
+	type reportHash struct {
+		id   int64
+		hash string
+	}
+	var pending []reportHash
+	for rows.Next() {
+		var id int64
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning report row: %w", err)
+		}
+		pending = append(pending, reportHash{id: id, hash: contentHash([]byte(content))})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating report rows: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	for _, r := range pending {
+		_, err := tx.ExecContext(ctx, `
+		UPDATE OR IGNORE reports SET content_hash = ? WHERE id = ?;`, r.hash, r.id)
+		if err != nil {
+			return fmt.Errorf("error backfilling content hash of report %d: %w", r.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing content hash backfill: %w", err)
+	}
 	return nil
 }
 
+// contentHash returns the hex encoded SHA-256 of a report's content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// AddReportContent stores content as a new report under filename and returns its ID, deduplicating like
+// AddReportToDatabase. It is used for reports generated in-process rather than read from disk.
+func AddReportContent(ctx context.Context, db *sql.DB, filename string, content string, updateExisting bool) (int64, error) {
+	if err := ensureReportTable(ctx, db); err != nil {
+		return 0, err
+	}
+
+	return insertReportContent(ctx, db, filename, []byte(content), updateExisting)
+}
+
 // insertReport handles the actual insertion of a report
-func insertReport(ctx context.Context, db *sql.DB, reportPath string) error {
+func insertReport(ctx context.Context, db *sql.DB, reportPath string, updateExisting bool) (int64, error) {
 	content, err := os.ReadFile(reportPath)
 	if err != nil {
-		return fmt.Errorf("error reading report file: %w", err)
+		return 0, fmt.Errorf("error reading report file: %w", err)
 	}
 
-	insertSQL := `
-	INSERT INTO reports (content, filename, created_at, updated_at)
-	VALUES (?, ?, ?, ?);`
+	return insertReportContent(ctx, db, reportPath, content, updateExisting)
+}
 
+// insertReportContent inserts a report, or resolves the existing one with the same content, in a single statement, so
+// that concurrent adds of the same content get the one stored report's ID rather than failing on the unique index
+func insertReportContent(ctx context.Context, db *sql.DB, reportPath string, content []byte, updateExisting bool) (int64, error) {
+	hash := contentHash(content)
 	now := time.Now().UTC()
 
-	result, err := db.ExecContext(ctx, insertSQL, string(content), reportPath, now, now)
+	if updateExisting {
+		var id int64
+		err := db.QueryRowContext(ctx, `
+		INSERT INTO reports (content, filename, content_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(content_hash) DO UPDATE SET filename = excluded.filename, updated_at = excluded.updated_at
+		RETURNING id;`, string(content), reportPath, hash, now, now).Scan(&id)
+		if err != nil {
+			return 0, fmt.Errorf("error inserting report into database: %w", err)
+		}
+		return id, nil
+	}
+
+	// DO NOTHING returns no row when the content is already stored
+	var id int64
+	err := db.QueryRowContext(ctx, `
+	INSERT INTO reports (content, filename, content_hash, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(content_hash) DO NOTHING
+	RETURNING id;`, string(content), reportPath, hash, now, now).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("error inserting report into database: %w", err)
+	}
+
+	err = db.QueryRowContext(ctx, `
+	SELECT id FROM reports WHERE content_hash = ?;`, hash).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("error inserting report into database: %w", err)
+		return 0, fmt.Errorf("error looking up existing report: %w", err)
+	}
+	return id, ErrReportExists
+}
+
+// AddReportsBatch adds the report files at paths to the database in a single transaction, using one prepared
+// statement, and returns the IDs of the inserted reports in order. Files that cannot be read are skipped rather than
+// aborting the batch, as are files whose content is already stored (ErrReportExists); both are reported, keyed by
+// path, in an *app.MultiError returned alongside the IDs of the reports that were inserted. Any database error rolls
+// back the whole batch.
+func AddReportsBatch(ctx context.Context, db *sql.DB, paths []string) ([]int64, error) {
+	if err := ensureReportTable(ctx, db); err != nil {
+		return nil, err
 	}
 
-	_, err = result.LastInsertId()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error getting last insert ID: %w", err)
+		return nil, fmt.Errorf("error starting transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO reports (content, filename, content_hash, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?);`)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing report insert: %w", err)
+	}
+	defer stmt.Close()
+
+	existsStmt, err := tx.PrepareContext(ctx, `
+	SELECT COUNT(*) FROM reports WHERE content_hash = ?;`)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing report lookup: %w", err)
+	}
+	defer existsStmt.Close()
+
+	var ids []int64
+	var skipped app.MultiError
+	for _, reportPath := range paths {
+		content, err := os.ReadFile(reportPath)
+		if err != nil {
+			skipped.Add(reportPath, fmt.Errorf("error reading report file: %w", err))
+			continue
+		}
+
+		hash := contentHash(content)
+		var existing int
+		if err := existsStmt.QueryRowContext(ctx, hash).Scan(&existing); err != nil {
+			return nil, fmt.Errorf("error checking for existing report %s: %w", reportPath, err)
+		}
+		if existing > 0 {
+			skipped.Add(reportPath, ErrReportExists)
+			continue
+		}
+
+		now := time.Now().UTC()
+		result, err := stmt.ExecContext(ctx, string(content), reportPath, hash, now, now)
+		if err != nil {
+			return nil, fmt.Errorf("error inserting report %s into database: %w", reportPath, err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("error getting last insert ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing reports: %w", err)
+	}
+
+	return ids, skipped.ErrorOrNil()
 }
 
 // GetReport retrieves a report by ID
@@ -92,6 +289,15 @@ func GetReport(ctx context.Context, db *sql.DB, id int64) (*Report, error) {
 	return report, nil
 }
 
+// CountReports returns the number of stored reports
+func CountReports(ctx context.Context, db *sql.DB) (int64, error) {
+	var count int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reports;`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting reports: %w", err)
+	}
+	return count, nil
+}
+
 // ListReports returns all reports
 func ListReports(ctx context.Context, db *sql.DB) ([]Report, error) {
 	query := `
@@ -127,3 +333,70 @@ func ListReports(ctx context.Context, db *sql.DB) ([]Report, error) {
 
 	return reports, nil
 }
+
+// ListReportsBetween returns the reports created in [from, to), newest first. Only the summary columns are selected;
+// Content is left empty.
+func ListReportsBetween(ctx context.Context, db *sql.DB, from, to time.Time) ([]Report, error) {
+	query := `
+	SELECT id, filename, created_at, updated_at
+	FROM reports
+	WHERE created_at >= ? AND created_at < ?
+	ORDER BY created_at DESC;`
+
+	rows, err := db.QueryContext(ctx, query, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error querying reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []Report
+	for rows.Next() {
+		var r Report
+		err := rows.Scan(
+			&r.ID,
+			&r.Filename,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning report row: %w", err)
+		}
+		reports = append(reports, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating report rows: %w", err)
+	}
+
+	return reports, nil
+}
+
+// DeleteReportsOlderThan deletes the reports created before cutoff and returns how many were removed. The delete runs
+// in a transaction, and since it only removes what is already past the cutoff it is safe to run repeatedly, e.g. as a
+// scheduled retention job.
+func DeleteReportsOlderThan(ctx context.Context, db *sql.DB, cutoff time.Time) (int64, error) {
+	if err := ensureReportTable(ctx, db); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+	DELETE FROM reports WHERE created_at < ?;`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("error deleting reports: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error checking deleted reports: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing report deletion: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,211 @@
+package reports_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+	"vmuser/database/dbtest"
+	"vmuser/ext/app"
+	"vmuser/pkg/reports"
+)
+
+func TestAddReportContentDeduplicates(t *testing.T) {
+	db, err := dbtest.GetTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	id, err := reports.AddReportContent(ctx, db, "first.md", "# Report", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dupID, err := reports.AddReportContent(ctx, db, "second.md", "# Report", false)
+	if !errors.Is(err, reports.ErrReportExists) || dupID != id {
+		t.Fatalf("duplicate = (%d, %v), want (%d, ErrReportExists)", dupID, err, id)
+	}
+	if _, err := reports.AddReportContent(ctx, db, "second.md", "# Report", true); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := reports.GetReport(ctx, db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Content != "# Report" || report.Filename != "second.md" {
+		t.Errorf("report = %+v, want the content renamed to second.md", report)
+	}
+
+	count, err := reports.CountReports(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+}
+
+func TestEnsureSchemaBackfillsContentHashes(t *testing.T) {
+	db, err := dbtest.GetTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	// A reports table from before reports were deduplicated, holding the same content twice
+	_, err = db.ExecContext(ctx, `
+	DROP TABLE IF EXISTS reports;
+	CREATE TABLE reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		content TEXT NOT NULL,
+		filename TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO reports (content, filename)
+	VALUES ('# Old', 'old.md'), ('# Old', 'old-copy.md'), ('# Other', 'other.md');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reports.EnsureSchema(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := reports.AddReportContent(ctx, db, "new.md", "# Old", false)
+	if !errors.Is(err, reports.ErrReportExists) || id != 1 {
+		t.Fatalf("add of migrated content = (%d, %v), want (1, ErrReportExists)", id, err)
+	}
+	id, err = reports.AddReportContent(ctx, db, "new.md", "# Other", false)
+	if !errors.Is(err, reports.ErrReportExists) || id != 3 {
+		t.Fatalf("add of migrated content = (%d, %v), want (3, ErrReportExists)", id, err)
+	}
+}
+
+func TestAddReportContentConcurrentDuplicates(t *testing.T) {
+	db, err := dbtest.GetTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if err := reports.EnsureSchema(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	const adders = 8
+	ids := make([]int64, adders)
+	errs := make([]error, adders)
+	var wg sync.WaitGroup
+	for i := range adders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids[i], errs[i] = reports.AddReportContent(ctx, db, "same.md", "# Same", false)
+		}()
+	}
+	wg.Wait()
+
+	inserted := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			inserted++
+		case !errors.Is(err, reports.ErrReportExists):
+			t.Fatalf("add %d failed: %v", i, err)
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("add %d returned id %d, want %d", i, ids[i], ids[0])
+		}
+	}
+	if inserted != 1 {
+		t.Errorf("%d adds inserted the report, want 1", inserted)
+	}
+}
+
+func TestDeleteReportsOlderThan(t *testing.T) {
+	db, err := dbtest.GetTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	oldID, err := reports.AddReportContent(ctx, db, "old.md", "old", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newID, err := reports.AddReportContent(ctx, db, "new.md", "new", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().UTC().AddDate(0, 0, -40)
+	if _, err := db.ExecContext(ctx, `UPDATE reports SET created_at = ? WHERE id = ?`, old, oldID); err != nil {
+		t.Fatal(err)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -30)
+	for run, want := range []int64{1, 0} {
+		deleted, err := reports.DeleteReportsOlderThan(ctx, db, cutoff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted != want {
+			t.Errorf("run %d deleted %d reports, want %d", run+1, deleted, want)
+		}
+	}
+
+	if _, err := reports.GetReport(ctx, db, oldID); err == nil {
+		t.Error("old report still exists")
+	}
+	if _, err := reports.GetReport(ctx, db, newID); err != nil {
+		t.Errorf("new report was deleted: %v", err)
+	}
+}
+
+func TestAddReportsBatchReportsSkippedFiles(t *testing.T) {
+	db, err := dbtest.GetTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.md")
+	duplicate := filepath.Join(dir, "duplicate.md")
+	missing := filepath.Join(dir, "missing.md")
+	for _, path := range []string{first, duplicate} {
+		if err := os.WriteFile(path, []byte("# Same"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids, err := reports.AddReportsBatch(ctx, db, []string{first, missing, duplicate})
+	if len(ids) != 1 {
+		t.Errorf("ids = %v, want one inserted report", ids)
+	}
+
+	var multi *app.MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("err = %v, want an *app.MultiError", err)
+	}
+	if !errors.Is(err, reports.ErrReportExists) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("err = %v, want it to match ErrReportExists and fs.ErrNotExist", err)
+	}
+	var keys []string
+	for _, itemErr := range multi.Errors() {
+		keys = append(keys, itemErr.Key)
+	}
+	if want := []string{missing, duplicate}; !slices.Equal(keys, want) {
+		t.Errorf("failed items = %v, want %v", keys, want)
+	}
+}
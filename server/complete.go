@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"vmuser/ext/httpext/responses"
+	"vmuser/pkg/llm"
+	"vmuser/pkg/reports"
+)
+
+// CompleteRequest is the body of POST /api/v1/complete
+type CompleteRequest struct {
+	Prompt string `json:"prompt"`
+	// Filename names the report the completion is saved as; a timestamped name is used when empty
+	Filename string `json:"filename,omitempty"`
+}
+
+// HandlerComplete runs an LLM completion for the posted prompt and streams it to the client over SSE as it is
+// generated. Once the completion finishes successfully it is saved as a report. The completion runs under the
+// request's context, so a client disconnect aborts the call to the provider and nothing is saved.
+func HandlerComplete(db *sql.DB, client llm.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if client == nil {
+			responses.WriteJSONError(w, http.StatusServiceUnavailable, "No LLM provider configured", "")
+			return
+		}
+
+		var req CompleteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if IsBodyTooLarge(err) {
+				responses.WriteJSONError(w, http.StatusRequestEntityTooLarge, "Request body too large", "")
+				return
+			}
+			responses.WriteJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+		if strings.TrimSpace(req.Prompt) == "" {
+			responses.WriteJSONError(w, http.StatusBadRequest, "Prompt is required", "")
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		contentChan, errChan := client.CompleteStream(ctx, req.Prompt)
+		tee := teeCompletion(ctx, contentChan, errChan)
+		responses.StreamStringChanToClientSSEWithCancel(ctx, cancel, w, tee.contentChan, tee.errChan)
+
+		completion, err := tee.wait()
+		if err != nil {
+			if r.Context().Err() == nil {
+				slog.Error("Error running completion", "error", err)
+			}
+			return
+		}
+
+		filename := req.Filename
+		if filename == "" {
+			filename = fmt.Sprintf("completion-%s.md", time.Now().UTC().Format("20060102T150405Z"))
+		}
+		id, err := reports.AddReportContent(r.Context(), db, filename, completion, false)
+		switch {
+		case errors.Is(err, reports.ErrReportExists):
+			slog.Info("Completion already stored as a report", "id", id)
+		case err != nil:
+			slog.Error("Error saving completion as report", "error", err)
+		default:
+			slog.Info("Saved completion as report", "id", id, "filename", filename)
+		}
+	}
+}
+
+// completionTee forwards a completion stream to the SSE writer while keeping the raw text, which the writer only
+// returns with newlines rewritten for display.
+type completionTee struct {
+	contentChan chan string
+	errChan     chan error
+	done        chan struct{}
+
+	completion strings.Builder
+	err        error
+}
+
+// teeCompletion starts forwarding contentChan and errChan. Streaming must end, cancelling ctx, before wait is called.
+func teeCompletion(ctx context.Context, contentChan <-chan string, errChan <-chan error) *completionTee {
+	t := &completionTee{
+		contentChan: make(chan string),
+		errChan:     make(chan error, 1),
+		done:        make(chan struct{}),
+	}
+
+	go func() {
+		defer close(t.done)
+		defer close(t.contentChan)
+
+		for chunk := range contentChan {
+			t.completion.WriteString(chunk)
+			select {
+			case t.contentChan <- chunk:
+			case <-ctx.Done():
+				t.err = ctx.Err()
+				return
+			}
+		}
+
+		// The producer closes errChan before contentChan, so any error is already buffered
+		select {
+		case err := <-errChan:
+			if err != nil {
+				t.err = err
+				t.errChan <- err
+				// Hold contentChan open so the writer sees the error rather than a clean end of stream
+				<-ctx.Done()
+			}
+		case <-ctx.Done():
+			t.err = ctx.Err()
+		}
+	}()
+
+	return t
+}
+
+// wait returns the full completion, or the error that cut it short
+func (t *completionTee) wait() (string, error) {
+	<-t.done
+	if t.err != nil {
+		return "", t.err
+	}
+	return t.completion.String(), nil
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"vmuser/ext/httpext/responses"
+)
+
+// fakeLLM streams chunks and then err, if set
+type fakeLLM struct {
+	chunks []string
+	err    error
+}
+
+func (f fakeLLM) Complete(ctx context.Context, prompt string) (string, error) {
+	return strings.Join(f.chunks, ""), f.err
+}
+
+func (f fakeLLM) CompleteStream(ctx context.Context, prompt string) (<-chan string, <-chan error) {
+	contentChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(contentChan)
+		defer close(errChan)
+		for _, chunk := range f.chunks {
+			select {
+			case contentChan <- chunk:
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+		}
+		if f.err != nil {
+			errChan <- f.err
+		}
+	}()
+	return contentChan, errChan
+}
+
+func TestHandlerCompleteRejectsBadRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		client fakeLLM
+		body   string
+		nilLLM bool
+		want   int
+	}{
+		{"no provider", fakeLLM{}, `{"prompt":"hi"}`, true, http.StatusServiceUnavailable},
+		{"invalid json", fakeLLM{}, `{"prompt":`, false, http.StatusBadRequest},
+		{"empty prompt", fakeLLM{}, `{"prompt":"  "}`, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := HandlerComplete(nil, tt.client)
+			if tt.nilLLM {
+				handler = HandlerComplete(nil, nil)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/complete", strings.NewReader(tt.body)))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerCompleteStreamsError(t *testing.T) {
+	client := fakeLLM{chunks: []string{"partial"}, err: errors.New("provider overloaded")}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/complete", strings.NewReader(`{"prompt":"hi"}`))
+	// db is nil: a failed completion must not be saved
+	HandlerComplete(nil, client)(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: message\ndata: partial") {
+		t.Errorf("missing streamed content: %q", body)
+	}
+	if !strings.Contains(body, "event: error\ndata: provider overloaded") {
+		t.Errorf("missing error event: %q", body)
+	}
+}
+
+func TestTeeCompletionKeepsRawText(t *testing.T) {
+	client := fakeLLM{chunks: []string{"line one\n", "line two"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	contentChan, errChan := client.CompleteStream(ctx, "")
+	tee := teeCompletion(ctx, contentChan, errChan)
+
+	rec := httptest.NewRecorder()
+	displayed := responses.StreamStringChanToClientSSEWithCancel(ctx, cancel, rec, tee.contentChan, tee.errChan)
+
+	completion, err := tee.wait()
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if completion != "line one\nline two" {
+		t.Errorf("completion = %q, want raw text", completion)
+	}
+	if displayed != "line one<br>line two" {
+		t.Errorf("displayed = %q", displayed)
+	}
+}
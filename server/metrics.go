@@ -0,0 +1,84 @@
+package server
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+	"vmuser/ext/httpext"
+)
+
+// Metrics holds the Prometheus collectors for the server's HTTP traffic, in a registry of its own.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics creates the server's HTTP metrics, alongside the standard Go runtime and process collectors.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "vmuser",
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests handled, by method, route pattern and status code.",
+		}, []string{"method", "path", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "vmuser",
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "path"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler returns the handler exposing the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware returns middleware recording the count and duration of every request. Requests are labelled with the
+// route pattern that matched (e.g. "GET /api/v1/reports/{id}/stream") rather than the raw path, to keep the number of
+// series bounded. A handler panic is recorded as a 500 before the panic continues to Recoverer.
+func (m *Metrics) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := httpext.NewResponseRecorder(w)
+
+			defer func() {
+				if p := recover(); p != nil {
+					rec.StatusCode = http.StatusInternalServerError
+					m.observe(r, rec.StatusCode, start)
+					panic(p)
+				}
+				m.observe(r, rec.StatusCode, start)
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+func (m *Metrics) observe(r *http.Request, status int, start time.Time) {
+	// The mux sets the matched pattern on the request it is handed
+	path := r.Pattern
+	if path == "" {
+		path = "unmatched"
+	}
+	m.requests.WithLabelValues(r.Method, path, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(r.Method, path).Observe(time.Since(start).Seconds())
+}
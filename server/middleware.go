@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"vmuser/ext/httpext/responses"
+)
+
+// Middleware wraps an http.Handler with additional behaviour.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with middleware, the first being the outermost.
+func Chain(h http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// Recoverer returns middleware that recovers from panics in later handlers, logs the panic value and stack trace,
+// and answers with a JSON 500 that does not expose either to the client. http.ErrAbortHandler is re-panicked so the
+// server can abort the response as intended.
+func Recoverer() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(rec)
+				}
+
+				slog.Error("Recovered from handler panic",
+					"panic", rec,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()))
+				responses.WriteJSONError(w, http.StatusInternalServerError, "Internal Server Error", "")
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MaxBodyBytes returns middleware that caps each request body at n bytes. Reading past the limit fails with an
+// *http.MaxBytesError, which handlers can detect with IsBodyTooLarge and answer with 413. A limit of 0 or less
+// disables the cap.
+func MaxBodyBytes(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		if n <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				responses.WriteJSONError(w, http.StatusRequestEntityTooLarge, "Request body too large", "")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsBodyTooLarge reports whether err comes from reading a request body past the MaxBodyBytes limit.
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
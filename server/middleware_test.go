@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"vmuser/ext/httpext/responses"
+)
+
+func TestRecovererReturnsJSON500(t *testing.T) {
+	handler := Recoverer()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["boom"] = 1
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	var body responses.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not a JSON error: %v", err)
+	}
+	if strings.Contains(rec.Body.String(), "goroutine") || strings.Contains(rec.Body.String(), "nil map") {
+		t.Fatalf("response leaks panic details: %s", rec.Body.String())
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	var readErr error
+	handler := MaxBodyBytes(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+		if IsBodyTooLarge(readErr) {
+			responses.WriteJSONError(w, http.StatusRequestEntityTooLarge, "Request body too large", "")
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/files", strings.NewReader("well over eight bytes"))
+	req.ContentLength = -1 // unknown length, as with chunked uploads, so the limit is hit while reading
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !IsBodyTooLarge(readErr) {
+		t.Fatalf("reading the body returned %v, want a body too large error", readErr)
+	}
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestMetricsMiddlewareRecordsPattern(t *testing.T) {
+	metrics := NewMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/reports/{id}/stream", func(w http.ResponseWriter, r *http.Request) {
+		responses.NoContent(w)
+	})
+	mux.Handle("GET /metrics", metrics.Handler())
+	handler := Chain(mux, Recoverer(), metrics.Middleware())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/reports/42/stream", nil))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := `vmuser_http_requests_total{method="GET",path="GET /api/v1/reports/{id}/stream",status="204"} 1`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Fatalf("metrics output does not contain %s", want)
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"vmuser/ext/httpext/responses"
+	"vmuser/pkg/reports"
+)
+
+// ReportStreamParagraphDelay is the pause between paragraphs when streaming a report
+const ReportStreamParagraphDelay = 150 * time.Millisecond
+
+// HandlerStreamReport streams a stored report's content over SSE, one paragraph at a time as if it were being
+// generated. With ?chunked=false the whole content is sent as a single event instead.
+func HandlerStreamReport(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			responses.WriteJSONError(w, http.StatusBadRequest, "Invalid report ID", r.PathValue("id"))
+			return
+		}
+
+		report, err := reports.GetReport(r.Context(), db, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.JsonDataNotFound(w, "report not found")
+			return
+		}
+		if err != nil {
+			slog.Error("Error loading report", "id", id, "error", err)
+			responses.WriteJSONError(w, http.StatusInternalServerError, "Error loading report", "")
+			return
+		}
+
+		if r.URL.Query().Get("chunked") == "false" {
+			err = responses.MimicFullSSEStreamForSingleString(w, report.Content)
+		} else {
+			err = responses.MimicChunkedSSEStreamForString(r.Context(), w, report.Content, ReportStreamParagraphDelay)
+		}
+		if err != nil && r.Context().Err() == nil {
+			slog.Error("Error streaming report", "id", id, "error", err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"vmuser/database/dbtest"
+	"vmuser/pkg/reports"
+)
+
+func TestHandlerStreamReport(t *testing.T) {
+	db, err := dbtest.GetTestConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	id, err := reports.AddReportContent(context.Background(), db, "report.md", "# Title\n\nBody line\nmore\n\nEnd", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := func(ctx context.Context, id string, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/reports/"+id+"/stream"+query, nil).WithContext(ctx)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		HandlerStreamReport(db)(rec, req)
+		return rec
+	}
+
+	// One event per paragraph, then the close event
+	rec := stream(context.Background(), strconv.FormatInt(id, 10), "")
+	want := "data: # Title\n\ndata: Body line<br>more\n\ndata: End\n\nevent: close\ndata: Stream ended\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("chunked stream =\n%q\nwant\n%q", got, want)
+	}
+
+	rec = stream(context.Background(), strconv.FormatInt(id, 10), "?chunked=false")
+	want = "data: # Title<br><br>Body line<br>more<br><br>End\n\nevent: close\ndata: Stream ended\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("unchunked stream =\n%q\nwant\n%q", got, want)
+	}
+
+	// A client that goes away mid-stream gets no further paragraphs and no close event
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(ReportStreamParagraphDelay/2, cancel)
+	rec = stream(ctx, strconv.FormatInt(id, 10), "")
+	if got := rec.Body.String(); got != "data: # Title\n\n" {
+		t.Errorf("cancelled stream = %q, want only the first paragraph", got)
+	}
+
+	if rec := stream(context.Background(), "999", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("missing report status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -2,26 +2,40 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 	"vmuser/ext/httpext/responses"
+	"vmuser/pkg/llm"
 )
 
+// DefaultMaxBodyBytes is the request body limit used when Config.MaxBodyBytes is not set.
+const DefaultMaxBodyBytes = 10 << 20 // 10MB, the size of the largest virtual file
+
 type Config struct {
 	Port string
+	// MaxBodyBytes caps the size of request bodies; 0 means DefaultMaxBodyBytes and a negative value disables the cap
+	MaxBodyBytes int64
+	// Metrics exposes Prometheus metrics on GET /metrics and instruments every request
+	Metrics bool
+	// LLM serves completions on POST /api/v1/complete; when nil the endpoint responds 503
+	LLM llm.Client
 }
 
 type Server struct {
-	config *Config
-	mux    *http.ServeMux
+	config  *Config
+	db      *sql.DB
+	mux     *http.ServeMux
+	handler http.Handler
 }
 
-func NewServer(config *Config) *Server {
+func NewServer(config *Config, db *sql.DB) *Server {
 	return &Server{
 		config: config,
+		db:     db,
 		mux:    http.NewServeMux(),
 	}
 }
@@ -33,7 +47,7 @@ func (s *Server) Start(appCtx context.Context) error {
 
 	srv := &http.Server{
 		Addr:    addr,
-		Handler: s.mux,
+		Handler: s.handler,
 	}
 
 	go func() {
@@ -55,6 +69,24 @@ func (s *Server) Start(appCtx context.Context) error {
 
 func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("GET /api/v1/{cmd}", HandlerGeneralCommand())
+	s.mux.HandleFunc("GET /api/v1/reports/{id}/stream", HandlerStreamReport(s.db))
+	s.mux.HandleFunc("POST /api/v1/complete", HandlerComplete(s.db, s.config.LLM))
+
+	maxBodyBytes := s.config.MaxBodyBytes
+	if maxBodyBytes == 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	// Recoverer is outermost so that panics anywhere in the chain are caught
+	middleware := []Middleware{Recoverer()}
+	if s.config.Metrics {
+		metrics := NewMetrics()
+		s.mux.Handle("GET /metrics", metrics.Handler())
+		middleware = append(middleware, metrics.Middleware())
+	}
+	middleware = append(middleware, MaxBodyBytes(maxBodyBytes))
+
+	s.handler = Chain(s.mux, middleware...)
 }
 
 func HandlerGeneralCommand() http.HandlerFunc {